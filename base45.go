@@ -0,0 +1,61 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+)
+
+const (
+	// RFC 9285的字符表，和字母数字模式的字符表顺序相同
+	base45Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+)
+
+// Base45编码，每2个字节编码成3个字符，最后1个字节编码成2个字符
+func EncodeBase45(b []byte) string {
+	s := make([]byte, 0, len(b)/2*3+len(b)%2*2)
+	for i := 0; i+1 < len(b); i += 2 {
+		n := int(b[i])<<8 | int(b[i+1])
+		s = append(s, base45Alphabet[n%45], base45Alphabet[n/45%45], base45Alphabet[n/2025])
+	}
+	if len(b)%2 == 1 {
+		n := int(b[len(b)-1])
+		s = append(s, base45Alphabet[n%45], base45Alphabet[n/45])
+	}
+	return string(s)
+}
+
+// Base45解码
+func DecodeBase45(s string) ([]byte, error) {
+	if len(s)%3 == 1 {
+		return nil, fmt.Errorf("invalid base45 length <%d>", len(s))
+	}
+	b := make([]byte, 0, len(s)/3*2+len(s)%3/2)
+	for i := 0; i < len(s); i += 3 {
+		n, m := 0, 1
+		for j := i; j < i+3 && j < len(s); j++ {
+			c := s[j]
+			if c != '0' && alphanumericTable[c] == 0 {
+				return nil, fmt.Errorf("invalid base45 character <%c>", c)
+			}
+			n += int(alphanumericTable[c]) * m
+			m *= 45
+		}
+		if i+3 <= len(s) {
+			if n > 0xffff {
+				return nil, fmt.Errorf("invalid base45 chunk <%s>", s[i:i+3])
+			}
+			b = append(b, byte(n>>8), byte(n))
+		} else {
+			if n > 0xff {
+				return nil, fmt.Errorf("invalid base45 chunk <%s>", s[i:])
+			}
+			b = append(b, byte(n))
+		}
+	}
+	return b, nil
+}
+
+// 二进制数据编码成Base45，使用字母数字模式，比字节模式的版本更小
+func EncodeBinaryCompact(data []byte, level Level) (image.Image, error) {
+	return Image(EncodeBase45(data), level)
+}
@@ -0,0 +1,39 @@
+package qrcode
+
+import (
+	"testing"
+)
+
+func TestBase45(t *testing.T) {
+	// RFC 9285的示例
+	for _, c := range [][2]string{
+		{"AB", "BB8"},
+		{"Hello!!", "%69 VD92EX0"},
+		{"base-45", "UJCLQE7W581"},
+		{"ietf!", "QED8WEX0"},
+		{"", ""},
+	} {
+		if s := EncodeBase45([]byte(c[0])); s != c[1] {
+			t.Fatalf("encode <%s> got <%s>", c[0], s)
+		}
+		b, err := DecodeBase45(c[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != c[0] {
+			t.Fatalf("decode <%s> got <%s>", c[1], b)
+		}
+	}
+	for _, s := range []string{"GGW", "A", "ab", "ZZ"} {
+		if _, err := DecodeBase45(s); err == nil {
+			t.Fatalf("expect error for <%s>", s)
+		}
+	}
+	data := make([]byte, 256)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	if m := analysisMode(EncodeBase45(data)); m != alphanumericMode {
+		t.Fatalf("expect alphanumeric mode, got %s", modeString[m])
+	}
+}
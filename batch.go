@@ -0,0 +1,167 @@
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"runtime"
+)
+
+// 批量生成的一项
+type BatchItem struct {
+	Payload  Payload              // 内容，字符串可以使用PlainText
+	Options  *Options             // 选项，nil使用默认值
+	Compress png.CompressionLevel // PNG的压缩级别
+}
+
+// 批量生成的结果
+type BatchResult struct {
+	Index int    // 在输入中的序号，从0开始
+	Data  []byte // PNG图像
+	Err   error  // 错误
+}
+
+// 批量生成中的一项
+type batchJob struct {
+	index  int
+	item   BatchItem
+	result chan BatchResult
+}
+
+// 从items读取每一项，使用workers个goroutine生成PNG图像，按照输入的顺序返回结果。
+// workers小于等于0使用GOMAXPROCS。items关闭以后，返回所有结果，然后关闭返回的通道。
+// ctx取消以后停止生成，关闭返回的通道，没有返回的项可以通过ctx.Err()判断原因
+func Batch(ctx context.Context, items <-chan BatchItem, workers int) <-chan BatchResult {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	jobs := make(chan *batchJob)
+	// 按照输入顺序等待结果，同时限制正在处理的项的个数
+	pending := make(chan *batchJob, workers)
+	out := make(chan BatchResult)
+	// 分发
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for i := 0; ; i++ {
+			var item BatchItem
+			var ok bool
+			select {
+			case item, ok = <-items:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			j := &batchJob{index: i, item: item, result: make(chan BatchResult, 1)}
+			select {
+			case pending <- j:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- j:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	// 生成
+	for i := 0; i < workers; i++ {
+		go func() {
+			q := _pool.Get().(*qrCode)
+			defer _pool.Put(q)
+			var img image.Paletted
+			var buf bytes.Buffer
+			for j := range jobs {
+				r := BatchResult{Index: j.index}
+				if r.Err = ctx.Err(); r.Err == nil {
+					r.Data, r.Err = q.batchPNG(&j.item, &img, &buf)
+				}
+				j.result <- r
+			}
+		}()
+	}
+	// 按顺序返回
+	go func() {
+		defer close(out)
+		for j := range pending {
+			var r BatchResult
+			select {
+			case r = <-j.result:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case out <- r:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// 和Batch一样，输入是切片
+func BatchSlice(ctx context.Context, items []BatchItem, workers int) <-chan BatchResult {
+	c := make(chan BatchItem)
+	go func() {
+		defer close(c)
+		for _, item := range items {
+			select {
+			case c <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return Batch(ctx, c, workers)
+}
+
+// 生成一项的PNG图像，纯色使用q和img的缓存，渐变使用StyledImage。
+// Payload是调用者的代码，panic转换成错误，不能让整个进程退出
+func (q *qrCode) batchPNG(item *BatchItem, img *image.Paletted, buf *bytes.Buffer) (data []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			data, err = nil, fmt.Errorf("batch item panic: %v", r)
+		}
+	}()
+	if item.Payload == nil {
+		return nil, errors.New("nil payload")
+	}
+	str, err := item.Payload.Text()
+	if err != nil {
+		return nil, err
+	}
+	opt := item.Options
+	var m image.Image
+	if opt.colors().solid() {
+		err = q.EncodeOptions(str, opt)
+		if err != nil {
+			return nil, err
+		}
+		err = q.DrawImage(img, opt)
+		if err != nil {
+			return nil, err
+		}
+		m = img
+	} else {
+		m, err = StyledImage(str, opt)
+		if err != nil {
+			return nil, err
+		}
+	}
+	buf.Reset()
+	enc := png.Encoder{
+		CompressionLevel: item.Compress,
+	}
+	err = enc.Encode(buf, m)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), buf.Bytes()...), nil
+}
@@ -0,0 +1,86 @@
+package qrcode
+
+import (
+	"bytes"
+	"context"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// Text会panic的Payload
+type testPanicPayload struct{}
+
+func (testPanicPayload) Text() (string, error) {
+	panic("boom")
+}
+
+func TestBatch(t *testing.T) {
+	var items []BatchItem
+	for i := 0; i < 50; i++ {
+		items = append(items, BatchItem{Payload: PlainText(strings.Repeat("a", i*20))})
+	}
+	// 太长，纠错等级不对，panic
+	items[7].Payload = PlainText(strings.Repeat("a", 8000))
+	items[13].Options = &Options{Level: 7}
+	items[15].Payload = testPanicPayload{}
+	// 非默认选项
+	items[9].Options = &Options{Level: LevelH, ModuleSize: 2, Colors: &Colors{Finder: Solid{color.RGBA{0, 0, 128, 255}}}}
+	// 渐变
+	items[11].Options = &Options{Colors: &Colors{Data: &LinearGradient{From: color.Black, To: color.RGBA{0, 0, 128, 255}}}}
+	i := 0
+	for r := range BatchSlice(context.Background(), items, 4) {
+		if r.Index != i {
+			t.Fatalf("index got %d, want %d", r.Index, i)
+		}
+		if i == 7 || i == 13 || i == 15 {
+			if r.Err == nil {
+				t.Fatal("expect error")
+			}
+		} else {
+			if r.Err != nil {
+				t.Fatal(r.Err)
+			}
+			str, _ := items[i].Payload.Text()
+			if i == 9 || i == 11 {
+				img, _ := StyledImage(str, items[i].Options)
+				m, err := png.Decode(bytes.NewReader(r.Data))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if m.Bounds() != img.Bounds() {
+					t.Fatalf("item %d size differs", i)
+				}
+				for y := 0; y < m.Bounds().Dy(); y++ {
+					for x := 0; x < m.Bounds().Dx(); x++ {
+						if !sameColor(m.At(x, y), img.At(x, y)) {
+							t.Fatalf("item %d differs at (%d,%d)", i, x, y)
+						}
+					}
+				}
+			} else {
+				var buf bytes.Buffer
+				PNG(&buf, str, LevelL, png.DefaultCompression)
+				if !bytes.Equal(buf.Bytes(), r.Data) {
+					t.Fatalf("item %d differs", i)
+				}
+			}
+		}
+		i++
+	}
+	if i != len(items) {
+		t.Fatalf("got %d results", i)
+	}
+	// 取消
+	ctx, cancel := context.WithCancel(context.Background())
+	c := make(chan BatchItem)
+	out := Batch(ctx, c, 2)
+	c <- BatchItem{Payload: PlainText(testStr)}
+	if r := <-out; r.Err != nil || r.Index != 0 {
+		t.Fatalf("unexpected %+v", r)
+	}
+	cancel()
+	for range out {
+	}
+}
@@ -0,0 +1,325 @@
+package qrcode
+
+import (
+	"math/bits"
+)
+
+const (
+	// 每行最多的uint64个数，版本40是177个模块
+	maxMatrixWords = 3
+	// mark图在两个方向上的周期
+	markPeriod = 12
+)
+
+var (
+	// 每种mark的行，markRowTable[i][y%12]的第x个bit是markFunc[i](x, y)
+	markRowTable [maxMark][markPeriod][maxMatrixWords]uint64
+	// 每种mark的列，markColTable[i][x%12]的第y个bit是markFunc[i](x, y)
+	markColTable [maxMark][markPeriod][maxMatrixWords]uint64
+	// 规则3的1:1:3:1:1，true是黑色
+	finderLikePattern = [...]bool{true, false, true, true, true, false, true}
+)
+
+func init() {
+	initMarkTable()
+}
+
+// 初始化mark表
+func initMarkTable() {
+	for i := 0; i < maxMark; i++ {
+		for j := 0; j < markPeriod; j++ {
+			for k := 0; k < maxMatrixWords*64; k++ {
+				if markFunc[i](k, j) {
+					markRowTable[i][j][k>>6] |= 1 << (k & 63)
+				}
+				if markFunc[i](j, k) {
+					markColTable[i][j][k>>6] |= 1 << (k & 63)
+				}
+			}
+		}
+	}
+}
+
+// 每个模块1个bit的矩阵，1是黑色，每行是uint64数组，第x个模块是第x/64个uint64的第x%64个bit
+type bitMatrix struct {
+	size  int      // 边长
+	words int      // 每行的uint64个数
+	bits  []uint64 // 数据
+}
+
+// 设置边长，清空数据
+func (m *bitMatrix) Reset(size int) {
+	m.size = size
+	m.words = (size + 63) / 64
+	n := m.words * size
+	if cap(m.bits) < n {
+		m.bits = make([]uint64, n)
+		return
+	}
+	m.bits = m.bits[:n]
+	for i := range m.bits {
+		m.bits[i] = 0
+	}
+}
+
+// 第y行
+func (m *bitMatrix) Row(y int) []uint64 {
+	return m.bits[y*m.words : (y+1)*m.words]
+}
+
+// 是否黑色
+func (m *bitMatrix) Get(x, y int) bool {
+	return m.Row(y)[x>>6]&(1<<(x&63)) != 0
+}
+
+// 设置颜色
+func (m *bitMatrix) Set(x, y int, black bool) {
+	if black {
+		m.Row(y)[x>>6] |= 1 << (x & 63)
+	} else {
+		m.Row(y)[x>>6] &^= 1 << (x & 63)
+	}
+}
+
+// 转置，m的第x行是s的第x列
+func (m *bitMatrix) Transpose(s *bitMatrix) {
+	m.Reset(s.size)
+	for y := 0; y < s.size; y++ {
+		row := s.Row(y)
+		for i, w := range row {
+			for w != 0 {
+				x := i<<6 + bits.TrailingZeros64(w)
+				w &= w - 1
+				m.Row(x)[y>>6] |= 1 << (y & 63)
+			}
+		}
+	}
+}
+
+// 复制
+func (m *bitMatrix) Copy(s *bitMatrix) {
+	m.size, m.words = s.size, s.words
+	m.bits = append(m.bits[:0], s.bits...)
+}
+
+// 黑色模块的个数
+func (m *bitMatrix) Count() int {
+	n := 0
+	for _, w := range m.bits {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// mark使用的矩阵，同时保存行和列两个方向，方便评分
+type markMatrix struct {
+	rows bitMatrix // 行
+	cols bitMatrix // 列，rows的转置
+}
+
+// 设置边长，清空数据
+func (m *markMatrix) Reset(size int) {
+	m.rows.Reset(size)
+	m.cols.Reset(size)
+}
+
+// 复制
+func (m *markMatrix) Copy(s *markMatrix) {
+	m.rows.Copy(&s.rows)
+	m.cols.Copy(&s.cols)
+}
+
+// 根据rows生成cols
+func (m *markMatrix) Transpose() {
+	m.cols.Transpose(&m.rows)
+}
+
+// 使用第i种mark，data中是1的模块，pix的颜色取反
+func (m *markMatrix) Mark(pix, data *markMatrix, i int) {
+	markBits(&m.rows, &pix.rows, &data.rows, &markRowTable[i])
+	markBits(&m.cols, &pix.cols, &data.cols, &markColTable[i])
+}
+
+// m = pix ^ (mark & data)
+func markBits(m, pix, data *bitMatrix, mark *[markPeriod][maxMatrixWords]uint64) {
+	m.size, m.words = pix.size, pix.words
+	if cap(m.bits) < len(pix.bits) {
+		m.bits = make([]uint64, len(pix.bits))
+	}
+	m.bits = m.bits[:len(pix.bits)]
+	for y := 0; y < pix.size; y++ {
+		r, p, d := m.Row(y), pix.Row(y), data.Row(y)
+		k := &mark[y%markPeriod]
+		for i := range r {
+			r[i] = p[i] ^ (k[i] & d[i])
+		}
+	}
+}
+
+// 设置(x,y)为黑色，同时修改rows和cols
+func (m *markMatrix) Set(x, y int) {
+	m.rows.Set(x, y, true)
+	m.cols.Set(y, x, true)
+}
+
+// 评分，ISO/IEC 18004 7.8.3的4条规则之和
+func (m *markMatrix) Score() int {
+	return m.evaluation1() + m.evaluation2() + m.evaluation3() + m.evaluation4()
+}
+
+// 规则1，行或列中5个以上连续相同颜色的点，+3分，之后每多1个点+1分
+func (m *markMatrix) evaluation1() int {
+	score := 0
+	for _, b := range []*bitMatrix{&m.rows, &m.cols} {
+		var t [maxMatrixWords]uint64
+		for y := 0; y < b.size; y++ {
+			// 颜色变化的位置，第x个bit是第x个点和第x-1个点不同
+			row := b.Row(y)
+			shiftLeft(t[:b.words], row, 1)
+			for i := range row {
+				t[i] ^= row[i]
+			}
+			t[0] &^= 1
+			lowBits(t[:b.words], b.size)
+			last := 0
+			for i := 0; i < b.words; i++ {
+				for w := t[i]; w != 0; w &= w - 1 {
+					x := i<<6 + bits.TrailingZeros64(w)
+					score += runScore(x - last)
+					last = x
+				}
+			}
+			score += runScore(b.size - last)
+		}
+	}
+	return score
+}
+
+// n个连续颜色的点的分数
+func runScore(n int) int {
+	if n < 5 {
+		return 0
+	}
+	return n - 2
+}
+
+// 规则2，每个相同颜色的2*2块，+3分，块可以重叠
+func (m *markMatrix) evaluation2() int {
+	b := &m.rows
+	var s0, s1, t [maxMatrixWords]uint64
+	n := 0
+	for y := 0; y+1 < b.size; y++ {
+		r0, r1 := b.Row(y), b.Row(y+1)
+		shiftRight(s0[:b.words], r0, 1)
+		shiftRight(s1[:b.words], r1, 1)
+		for i := range r0 {
+			t[i] = ^(r0[i] ^ s0[i]) & ^(r0[i] ^ r1[i]) & ^(r1[i] ^ s1[i])
+		}
+		lowBits(t[:b.words], b.size-1)
+		for i := 0; i < b.words; i++ {
+			n += bits.OnesCount64(t[i])
+		}
+	}
+	return n * 3
+}
+
+// 规则3，行或列中的1:1:3:1:1（黑白黑黑黑白黑），前面或者后面有4个白点，+40分。
+// 符号之外的空白区域算作白点
+func (m *markMatrix) evaluation3() int {
+	return evaluation3Bits(&m.rows) + evaluation3Bits(&m.cols)
+}
+
+// 在b的每一行查找1:1:3:1:1
+func evaluation3Bits(b *bitMatrix) int {
+	if b.size < len(finderLikePattern) {
+		return 0
+	}
+	n := 0
+	var t, s, l, r [maxMatrixWords]uint64
+	for y := 0; y < b.size; y++ {
+		row := b.Row(y)
+		// 第x个bit是从x开始匹配
+		for i := range row {
+			t[i] = ^uint64(0)
+		}
+		for k, c := range finderLikePattern {
+			shiftRight(s[:b.words], row, k)
+			for i := range row {
+				if c {
+					t[i] &= s[i]
+				} else {
+					t[i] &^= s[i]
+				}
+			}
+		}
+		lowBits(t[:b.words], b.size-len(finderLikePattern)+1)
+		// l的第x个bit是x前面4个点中有黑点，r的第x个bit是x+7开始的4个点中有黑点，
+		// 超出边长的bit都是0，也就是白点
+		for i := range l {
+			l[i], r[i] = 0, 0
+		}
+		for k := 1; k <= 4; k++ {
+			shiftLeft(s[:b.words], row, k)
+			for i := range row {
+				l[i] |= s[i]
+			}
+			shiftRight(s[:b.words], row, len(finderLikePattern)-1+k)
+			for i := range row {
+				r[i] |= s[i]
+			}
+		}
+		for i := 0; i < b.words; i++ {
+			n += bits.OnesCount64(t[i] & ^(l[i] & r[i]))
+		}
+	}
+	return n * 40
+}
+
+// 规则4，黑点的比例和50%的差距，每5%，+10分
+func (m *markMatrix) evaluation4() int {
+	n, total := m.rows.Count(), m.rows.size*m.rows.size
+	return abs(n*2-total) * 10 / total * 10
+}
+
+// 绝对值
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// dst的第x个bit是src的第x-n个bit，n小于64
+func shiftLeft(dst, src []uint64, n int) {
+	var carry uint64
+	for i := range src {
+		dst[i] = src[i]<<n | carry
+		carry = src[i] >> (64 - n)
+	}
+}
+
+// dst的第x个bit是src的第x+n个bit，n小于64
+func shiftRight(dst, src []uint64, n int) {
+	if n == 0 {
+		copy(dst, src)
+		return
+	}
+	for i := range src {
+		dst[i] = src[i] >> n
+		if i+1 < len(src) {
+			dst[i] |= src[i+1] << (64 - n)
+		}
+	}
+}
+
+// 只保留前n个bit
+func lowBits(b []uint64, n int) {
+	for i := range b {
+		switch {
+		case n <= i<<6:
+			b[i] = 0
+		case n < (i+1)<<6:
+			b[i] &= 1<<(n&63) - 1
+		}
+	}
+}
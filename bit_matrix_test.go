@@ -0,0 +1,319 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+// 从字符串生成矩阵，'#'是黑色
+func testMarkMatrix(rows []string) *markMatrix {
+	m := new(markMatrix)
+	m.Reset(len(rows))
+	for y, r := range rows {
+		for x, c := range r {
+			if c == '#' {
+				m.Set(x, y)
+			}
+		}
+	}
+	return m
+}
+
+func TestScore(t *testing.T) {
+	white := func(n int) []string {
+		rows := make([]string, n)
+		for i := range rows {
+			rows[i] = strings.Repeat(".", n)
+		}
+		return rows
+	}
+	transpose := func(rows []string) []string {
+		cols := make([]string, len(rows))
+		for x := range rows {
+			var b strings.Builder
+			for y := range rows {
+				b.WriteByte(rows[y][x])
+			}
+			cols[x] = b.String()
+		}
+		return cols
+	}
+	finder1 := append([]string{"#.###.#...."}, white(11)[1:]...)
+	finder2 := append([]string{"....#.###.#...."}, white(15)[1:]...)
+	finder3 := append([]string{"##.###.#.##"}, white(11)[1:]...)
+	line := append([]string{"#######"}, white(7)[1:]...)
+	dark := append(strings.Split(strings.Repeat("##########,", 6), ",")[:6], white(10)[6:]...)
+	// -1表示不检查
+	for i, c := range []struct {
+		rows  []string
+		score [4]int
+	}{
+		{white(5), [4]int{30, 48, 0, 100}},
+		{white(6), [4]int{48, 75, 0, 100}},
+		{[]string{"#.#.#.", ".#.#.#", "#.#.#.", ".#.#.#", "#.#.#.", ".#.#.#"}, [4]int{0, 0, 0, 0}},
+		{line, [4]int{63, -1, 0, -1}},
+		// 左边是符号之外的空白
+		{finder1, [4]int{-1, -1, 40, -1}},
+		{transpose(finder1), [4]int{-1, -1, 40, -1}},
+		// 两边都有4个白点，只算1次
+		{finder2, [4]int{-1, -1, 40, -1}},
+		// 两边都没有4个白点
+		{finder3, [4]int{-1, -1, 0, -1}},
+		// 60%
+		{dark, [4]int{-1, -1, -1, 20}},
+	} {
+		m := testMarkMatrix(c.rows)
+		score := [4]int{m.evaluation1(), m.evaluation2(), m.evaluation3(), m.evaluation4()}
+		for j := range score {
+			if c.score[j] >= 0 && score[j] != c.score[j] {
+				t.Fatalf("case %d rule %d got %d, want %d", i, j+1, score[j], c.score[j])
+			}
+		}
+	}
+}
+
+// 每个模块1个字节的参考实现，逐个模块放置数据，mark和评分，1是黑色
+type testByteMatrix struct {
+	size int
+	pix  [][]uint8
+}
+
+func newTestByteMatrix(size int) *testByteMatrix {
+	m := &testByteMatrix{size: size, pix: make([][]uint8, size)}
+	for y := range m.pix {
+		m.pix[y] = make([]uint8, size)
+	}
+	return m
+}
+
+func (m *testByteMatrix) clone() *testByteMatrix {
+	c := newTestByteMatrix(m.size)
+	for y := range m.pix {
+		copy(c.pix[y], m.pix[y])
+	}
+	return c
+}
+
+// 第i行，vertical是第i列
+func (m *testByteMatrix) line(i int, vertical bool) []uint8 {
+	if !vertical {
+		return m.pix[i]
+	}
+	l := make([]uint8, m.size)
+	for y := range l {
+		l[y] = m.pix[y][i]
+	}
+	return l
+}
+
+func (m *testByteMatrix) score() int {
+	score := 0
+	for _, vertical := range []bool{false, true} {
+		for i := 0; i < m.size; i++ {
+			l := m.line(i, vertical)
+			// 规则1
+			for x := 0; x < len(l); {
+				n := 1
+				for x+n < len(l) && l[x+n] == l[x] {
+					n++
+				}
+				if n >= 5 {
+					score += n - 2
+				}
+				x += n
+			}
+			// 规则3
+			light := func(x1, x2 int) bool {
+				for x := x1; x < x2; x++ {
+					if x >= 0 && x < len(l) && l[x] == 1 {
+						return false
+					}
+				}
+				return true
+			}
+			for x := 0; x+7 <= len(l); x++ {
+				if string(l[x:x+7]) == "\x01\x00\x01\x01\x01\x00\x01" && (light(x-4, x) || light(x+7, x+11)) {
+					score += 40
+				}
+			}
+		}
+	}
+	// 规则2
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			dark += int(m.pix[y][x])
+			if x+1 < m.size && y+1 < m.size {
+				c := m.pix[y][x]
+				if m.pix[y][x+1] == c && m.pix[y+1][x] == c && m.pix[y+1][x+1] == c {
+					score += 3
+				}
+			}
+		}
+	}
+	// 规则4
+	total := m.size * m.size
+	return score + abs(dark*2-total)*10/total*10
+}
+
+// 参考实现，返回最终的模块和mark编号
+func testByteEncode(q *qrCode) (*testByteMatrix, int) {
+	v := q.strEnc.version
+	l := versionLayout(v)
+	size := l.size
+	base := newTestByteMatrix(size)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if l.pattern.rows.Get(x, y) {
+				base.pix[y][x] = 1
+			}
+		}
+	}
+	// 版本信息
+	if v >= version7 {
+		ver := versionBitTable[v]
+		for k := 0; k < 18; k++ {
+			c := ver[17-k]
+			base.pix[size-11+k%3][k/3] = c
+			base.pix[k/3][size-11+k%3] = c
+		}
+	}
+	// 数据，从右下角开始，每次2列
+	i, up := 0, true
+	for x := size - 1; x > 0; x -= 2 {
+		if x == timingPattern {
+			x--
+		}
+		for n := 0; n < size; n++ {
+			y := n
+			if up {
+				y = size - 1 - n
+			}
+			for _, c := range []int{x, x - 1} {
+				if moduleRegion(v, c, y) != RegionData {
+					continue
+				}
+				if i < len(q.eccEnc.data)*8 {
+					base.pix[y][c] = q.eccEnc.data[i/8] >> (7 - i%8) & 1
+				}
+				i++
+			}
+		}
+		up = !up
+	}
+	var best *testByteMatrix
+	mark, minScore := 0, 0
+	for k := 0; k < maxMark; k++ {
+		m := base.clone()
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				if moduleRegion(v, x, y) == RegionData && markFunc[k](x, y) {
+					m.pix[y][x] ^= 1
+				}
+			}
+		}
+		// 格式信息，f[0]是最高位
+		f := formatBitTable[q.strEnc.Level][k]
+		for j := 0; j < 15; j++ {
+			c := f[14-j]
+			// 左上角
+			switch {
+			case j < 6:
+				m.pix[j][8] = c
+			case j < 8:
+				m.pix[j+1][8] = c
+			case j == 8:
+				m.pix[8][7] = c
+			default:
+				m.pix[8][14-j] = c
+			}
+			// 右上角和左下角
+			if j < 8 {
+				m.pix[8][size-1-j] = c
+			} else {
+				m.pix[size-15+j][8] = c
+			}
+		}
+		if s := m.score(); best == nil || s < minScore {
+			best, mark, minScore = m, k, s
+		}
+	}
+	return best, mark
+}
+
+// 和逐个模块的参考实现比较
+func TestBitMatrixReference(t *testing.T) {
+	q := new(qrCode)
+	q.init()
+	var strs []string
+	for _, s := range []string{"0123456789", "HELLO WORLD $%*+-./:", "hello, world! 你好"} {
+		for n := 1; n < 3000; n = n*3/2 + 1 {
+			strs = append(strs, strings.Repeat(s, n/len(s)+1)[:n])
+		}
+	}
+	versions := make(map[version]bool)
+	for _, s := range strs {
+		for l := LevelL; l < maxLevel; l++ {
+			if q.Encode(s, l) != nil {
+				continue
+			}
+			versions[q.strEnc.version] = true
+			size := q.drawMatrix()
+			m, mark := testByteEncode(q)
+			if mark != q.markNum {
+				t.Fatalf("length %d level %d mark got %d, want %d", len(s), l, q.markNum, mark)
+			}
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					if q.pix.rows.Get(x, y) != (m.pix[y][x] == 1) {
+						t.Fatalf("length %d level %d module (%d,%d) differs", len(s), l, x, y)
+					}
+				}
+			}
+		}
+	}
+	if len(versions) < 30 {
+		t.Fatalf("only %d versions covered", len(versions))
+	}
+}
+
+// 版本7以上，评分的候选图包括版本信息，每种mark的评分和参考实现相同
+func TestScoreVersionInformation(t *testing.T) {
+	q := new(qrCode)
+	q.init()
+	for _, s := range []string{strings.Repeat("HELLO WORLD ", 15), testStrV40[:3000]} {
+		if err := q.Encode(s, LevelM); err != nil {
+			t.Fatal(err)
+		}
+		v := q.strEnc.version
+		if v < version7 {
+			t.Fatalf("version %d", v+1)
+		}
+		l := versionLayout(v)
+		q.pix.Copy(&l.pattern)
+		q.drawData(l)
+		q.drawVersionInformation(&q.pix, l)
+		ver := versionBitTable[v]
+		for i := 0; i < maxMark; i++ {
+			q.markBuff.Mark(&q.pix, &l.data, i)
+			q.drawFormatInformation(&q.markBuff, i)
+			m := newTestByteMatrix(l.size)
+			for y := 0; y < l.size; y++ {
+				for x := 0; x < l.size; x++ {
+					if q.markBuff.rows.Get(x, y) {
+						m.pix[y][x] = 1
+					}
+				}
+			}
+			for k := 0; k < versionInfoBits; k++ {
+				c := ver[versionInfoBits-1-k]
+				if m.pix[l.size-11+k%3][k/3] != c || m.pix[k/3][l.size-11+k%3] != c {
+					t.Fatalf("version %d mark %d missing version information bit %d", v+1, i, k)
+				}
+			}
+			if got, want := q.markBuff.Score(), m.score(); got != want {
+				t.Fatalf("version %d mark %d score got %d, want %d", v+1, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+const (
+	defaultMinContrast = 3
+)
+
+var (
+	// 默认的背景和填充，避免每次转换成接口
+	defaultBackground color.Color = color.White
+	defaultPaint      Paint       = Solid{color.Black}
+)
+
+// 模块的颜色填充，x和y是模块中心在二维码中的相对位置，范围[0,1]
+type Paint interface {
+	At(x, y float64) color.Color
+}
+
+// 纯色
+type Solid struct {
+	color.Color
+}
+
+func (p Solid) At(x, y float64) color.Color {
+	return p.Color
+}
+
+// 线性渐变，从(X1,Y1)到(X2,Y2)，起点和终点相同时从左到右
+type LinearGradient struct {
+	From, To       color.Color
+	X1, Y1, X2, Y2 float64
+}
+
+func (p *LinearGradient) At(x, y float64) color.Color {
+	dx, dy := p.X2-p.X1, p.Y2-p.Y1
+	d := dx*dx + dy*dy
+	if d == 0 {
+		return lerpColor(p.From, p.To, x)
+	}
+	return lerpColor(p.From, p.To, ((x-p.X1)*dx+(y-p.Y1)*dy)/d)
+}
+
+// 径向渐变，圆心(CX,CY)，半径R，R<=0时圆心在中间，半径到四个角
+type RadialGradient struct {
+	Inner, Outer color.Color
+	CX, CY, R    float64
+}
+
+func (p *RadialGradient) At(x, y float64) color.Color {
+	cx, cy, r := p.CX, p.CY, p.R
+	if r <= 0 {
+		cx, cy, r = 0.5, 0.5, math.Sqrt2/2
+	}
+	return lerpColor(p.Inner, p.Outer, math.Hypot(x-cx, y-cy)/r)
+}
+
+// 两个颜色之间插值，t的范围[0,1]
+func lerpColor(c1, c2 color.Color, t float64) color.Color {
+	if t <= 0 {
+		return c1
+	}
+	if t >= 1 {
+		return c2
+	}
+	n1 := color.NRGBAModel.Convert(c1).(color.NRGBA)
+	n2 := color.NRGBAModel.Convert(c2).(color.NRGBA)
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.NRGBA{
+		R: lerp(n1.R, n2.R),
+		G: lerp(n1.G, n2.G),
+		B: lerp(n1.B, n2.B),
+		A: lerp(n1.A, n2.A),
+	}
+}
+
+// 颜色，每个区域可以使用不同的填充
+type Colors struct {
+	Background  color.Color // 背景，nil是白色
+	Finder      Paint       // finder patterns，nil使用Data
+	Alignment   Paint       // alignment patterns，nil使用Data
+	Timing      Paint       // timing patterns，nil使用Data
+	Data        Paint       // 数据模块，包括格式和版本信息，nil是黑色
+	MinContrast float64     // 黑色模块和背景的最小对比度，0使用默认值3
+}
+
+// 背景颜色
+func (c *Colors) background() color.Color {
+	if c == nil || c.Background == nil {
+		return defaultBackground
+	}
+	return c.Background
+}
+
+// 区域r的填充
+func (c *Colors) paint(r Region) Paint {
+	if c == nil {
+		return defaultPaint
+	}
+	var p Paint
+	switch r {
+	case RegionFinder:
+		p = c.Finder
+	case RegionAlignment:
+		p = c.Alignment
+	case RegionTiming:
+		p = c.Timing
+	}
+	if p == nil {
+		p = c.Data
+	}
+	if p == nil {
+		p = defaultPaint
+	}
+	return p
+}
+
+// 所有区域是否都是纯色
+func (c *Colors) solid() bool {
+	for r := Region(0); r < maxRegion; r++ {
+		if _, ok := c.paint(r).(Solid); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// 最小对比度
+func (c *Colors) minContrast() float64 {
+	if c == nil || c.MinContrast <= 0 {
+		return defaultMinContrast
+	}
+	return c.MinContrast
+}
+
+// 检查区域r的颜色fg和背景的对比度
+func (c *Colors) checkContrast(r Region, fg color.Color) error {
+	n := contrastRatio(fg, c.background())
+	if n < c.minContrast() {
+		return fmt.Errorf("contrast <%.2f> of %s modules less than <%.2f>", n, r, c.minContrast())
+	}
+	return nil
+}
+
+// 两个颜色是否相同
+func sameColor(c1, c2 color.Color) bool {
+	r1, g1, b1, a1 := c1.RGBA()
+	r2, g2, b2, a2 := c2.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+// 相对亮度，参考WCAG 2.0
+func luminance(c color.Color) float64 {
+	r, g, b, _ := c.RGBA()
+	f := func(v uint32) float64 {
+		s := float64(v) / 0xffff
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*f(r) + 0.7152*f(g) + 0.0722*f(b)
+}
+
+// 两个颜色的对比度，范围[1,21]
+func contrastRatio(c1, c2 color.Color) float64 {
+	l1, l2 := luminance(c1), luminance(c2)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// 按照opt的颜色，空白边和模块大小生成图像
+func StyledImage(str string, opt *Options) (image.Image, error) {
+	m, err := newMatrix(str, opt)
+	if err != nil {
+		return nil, err
+	}
+	c := opt.colors()
+	qz, ms := opt.quietZone(), opt.moduleSize()
+	n := (m.size + qz*2) * ms
+	img := image.NewRGBA(image.Rect(0, 0, n, n))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c.background()), image.Point{}, draw.Src)
+	err = m.colorRuns(c, func(x, y, n int, fg color.Color) {
+		rect := image.Rect(x+qz, y+qz, x+qz+n, y+qz+1)
+		rect.Min = rect.Min.Mul(ms)
+		rect.Max = rect.Max.Mul(ms)
+		draw.Draw(img, rect, image.NewUniform(fg), image.Point{}, draw.Src)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return img, nil
+}
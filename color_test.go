@@ -0,0 +1,64 @@
+package qrcode
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestStyledImage(t *testing.T) {
+	str := "Hello World!"
+	red := color.RGBA{R: 200, A: 255}
+	blue := color.RGBA{B: 128, A: 255}
+	bg := color.RGBA{R: 255, G: 255, B: 200, A: 255}
+	for _, c := range []struct {
+		opt        *Options
+		qz, ms     int
+		bg, finder color.Color
+		data       color.Color
+	}{
+		{nil, 4, 1, color.White, color.Black, color.Black},
+		{&Options{Level: LevelM, QuietZone: -1, ModuleSize: 2}, 0, 2, color.White, color.Black, color.Black},
+		{&Options{
+			Level:      LevelM,
+			QuietZone:  2,
+			ModuleSize: 3,
+			Colors:     &Colors{Background: bg, Finder: Solid{red}, Data: Solid{blue}},
+		}, 2, 3, bg, red, blue},
+	} {
+		img, err := Image(str, c.opt.level())
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := img.(*image.Paletted)
+		size := p.Stride - 8
+		s, err := StyledImage(str, c.opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := (size + c.qz*2) * c.ms
+		if s.Bounds() != image.Rect(0, 0, n, n) {
+			t.Fatalf("bounds got %v, want %d", s.Bounds(), n)
+		}
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				mx, my := x/c.ms-c.qz, y/c.ms-c.qz
+				want := c.bg
+				if mx >= 0 && my >= 0 && mx < size && my < size && p.Pix[(my+4)*p.Stride+mx+4] == _paletteBlack {
+					want = c.data
+					if moduleRegion(version((size-21)/4), mx, my) == RegionFinder {
+						want = c.finder
+					}
+				}
+				if !sameColor(s.At(x, y), want) {
+					t.Fatalf("pixel (%d,%d) got %v, want %v", x, y, s.At(x, y), want)
+				}
+			}
+		}
+	}
+	// 对比度不够
+	_, err := StyledImage(str, &Options{Colors: &Colors{Data: Solid{color.Gray{Y: 200}}}})
+	if err == nil {
+		t.Fatal("expect contrast error")
+	}
+}
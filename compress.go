@@ -0,0 +1,112 @@
+package qrcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"io"
+	"strings"
+)
+
+const (
+	// 二进制压缩数据的头部，控制字符+版本，普通文本不会使用，使用字节模式
+	compressedBinary = "\x1f\x01"
+	// Base45压缩数据的头部，标记+版本，使用字母数字模式
+	compressedBase45 = "Z1:"
+)
+
+// 压缩字符串，zlib压缩以后，在原始字符串，二进制和Base45中选择版本最小的，版本相同优先原始字符串。
+// 以压缩头部开头的字符串一定压缩，解压时不会混淆，zlib数据有adler32校验
+func CompressText(str string, level Level) (string, error) {
+	z := zlibCompress([]byte(str))
+	// 版本相同优先使用Base45，扫码器更容易处理
+	candidates := []string{str, compressedBase45 + EncodeBase45(z), compressedBinary + string(z)}
+	if hasCompressedHeader(str) {
+		candidates = candidates[1:]
+	}
+	best, bestVersion := "", version(0)
+	var err error
+	for _, s := range candidates {
+		v, e := analysisVersion(s, level, analysisMode(s))
+		if e != nil {
+			// 返回第一个错误，通常是原始字符串太长
+			if err == nil {
+				err = e
+			}
+			continue
+		}
+		if best == "" || v < bestVersion {
+			best, bestVersion = s, v
+		}
+	}
+	if best == "" {
+		return "", err
+	}
+	return best, nil
+}
+
+// 是否以压缩头部开头
+func hasCompressedHeader(str string) bool {
+	return strings.HasPrefix(str, compressedBinary) || strings.HasPrefix(str, compressedBase45)
+}
+
+// 解压扫码得到的字符串，返回解压后的字符串和是否压缩数据，没有压缩的原样返回。
+// 二进制头部的数据不正确返回错误，Base45头部的数据不正确当作普通文本。
+// 很多扫码器把字节模式的数据当作Latin-1，返回UTF-8字符串，也可以解压
+func DecompressText(str string) (string, bool, error) {
+	if strings.HasPrefix(str, compressedBinary) {
+		z := str[len(compressedBinary):]
+		b, err := zlibDecompress([]byte(z))
+		if err != nil {
+			// 有大于0x7F的字符时，Latin-1的字节比UTF-8少
+			if l, ok := latin1Bytes(z); ok && len(l) != len(z) {
+				b, err = zlibDecompress(l)
+			}
+		}
+		if err != nil {
+			return "", false, err
+		}
+		return string(b), true, nil
+	}
+	if strings.HasPrefix(str, compressedBase45) {
+		z, err := DecodeBase45(str[len(compressedBase45):])
+		if err != nil {
+			return str, false, nil
+		}
+		b, err := zlibDecompress(z)
+		if err != nil {
+			return str, false, nil
+		}
+		return string(b), true, nil
+	}
+	return str, false, nil
+}
+
+// 先压缩字符串再生成图像
+func CompressedImage(str string, level Level) (image.Image, error) {
+	s, err := CompressText(str, level)
+	if err != nil {
+		return nil, err
+	}
+	return Image(s, level)
+}
+
+// zlib压缩
+func zlibCompress(b []byte) []byte {
+	var buf bytes.Buffer
+	w, _ := zlib.NewWriterLevel(&buf, zlib.BestCompression)
+	w.Write(b)
+	w.Close()
+	return buf.Bytes()
+}
+
+// zlib解压
+func zlibDecompress(b []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zlib data: %v", err)
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
@@ -0,0 +1,60 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompressText(t *testing.T) {
+	// 重复的JSON，原始字符串超过版本40
+	long := `[` + strings.Repeat(`{"id":12345,"name":"example","tags":["a","b","c"]},`, 100) + `{}]`
+	if _, err := analysisVersion(long, LevelH, analysisMode(long)); err == nil {
+		t.Fatal("expect raw string too large")
+	}
+	for _, str := range []string{long, "hello", "Z:ABC", "Z1:ABC", "\x1f\x01xx"} {
+		s, err := CompressText(str, LevelH)
+		if err != nil {
+			t.Fatal(err)
+		}
+		compressed := str == long || hasCompressedHeader(str)
+		if compressed == (s == str) {
+			t.Fatalf("<%q> compressed %v, got <%q>", str, compressed, s)
+		}
+		d, ok, err := DecompressText(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d != str || ok != compressed {
+			t.Fatalf("decompress got <%q> %v", d, ok)
+		}
+	}
+	// 扫码器当作Latin-1返回的UTF-8字符串
+	s := compressedBinary + string(zlibCompress([]byte(long)))
+	r := make([]rune, len(s))
+	for i := 0; i < len(s); i++ {
+		r[i] = rune(s[i])
+	}
+	if string(r) == s {
+		t.Fatal("expect bytes greater than 0x7F")
+	}
+	if d, ok, err := DecompressText(string(r)); err != nil || !ok || d != long {
+		t.Fatalf("latin-1 got %v %v", ok, err)
+	}
+	// 不是CompressText生成的Base45头部，当作普通文本
+	if d, ok, err := DecompressText("Z1:HELLO"); err != nil || ok || d != "Z1:HELLO" {
+		t.Fatalf("got <%s> %v %v", d, ok, err)
+	}
+	if _, _, err := DecompressText(compressedBinary + "xx"); err == nil {
+		t.Fatal("expect error for invalid zlib data")
+	}
+	// 压缩以后还是太长
+	b := make([]byte, 4000)
+	x := uint32(1)
+	for i := range b {
+		x = x*1103515245 + 12345
+		b[i] = byte(x >> 16)
+	}
+	if _, err := CompressText(string(b), LevelH); err == nil {
+		t.Fatal("expect error")
+	}
+}
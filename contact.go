@@ -0,0 +1,420 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 联系人的格式
+type ContactFormat int
+
+const (
+	VCard3      ContactFormat = iota // vCard 3.0
+	VCard4                           // vCard 4.0
+	MeCard                           // MeCard
+	ContactAuto                      // 自动选择编码后数据最少的格式
+	maxContactFormat
+)
+
+const (
+	// vCard每行最多75个字节
+	vcardLineLength = 75
+)
+
+// 电话
+type Phone struct {
+	Type   string // 类型，例如"cell"，"work"，"home"，可以为空
+	Number string // 号码
+}
+
+// 地址
+type Address struct {
+	Type       string // 类型，例如"work"，"home"，可以为空
+	POBox      string // 邮政信箱
+	Extended   string // 扩展地址，例如房间号
+	Street     string // 街道
+	City       string // 城市
+	Region     string // 省或州
+	PostalCode string // 邮编
+	Country    string // 国家
+}
+
+// 地址的7个部分
+func (a *Address) fields() []string {
+	return []string{a.POBox, a.Extended, a.Street, a.City, a.Region, a.PostalCode, a.Country}
+}
+
+// 联系人
+type Contact struct {
+	Format       ContactFormat // 格式
+	FirstName    string        // 名
+	LastName     string        // 姓
+	Organization string        // 组织
+	Title        string        // 职位，MeCard不支持
+	Phones       []Phone       // 电话
+	Emails       []string      // 电子邮件
+	Addresses    []Address     // 地址
+	URL          string        // 网址
+	Note         string        // 备注
+}
+
+// 生成联系人字符串
+func (c *Contact) Text() (string, error) {
+	if c.FirstName == "" && c.LastName == "" {
+		return "", fmt.Errorf("contact name is empty")
+	}
+	switch c.Format {
+	case VCard3:
+		return c.vcard(false), nil
+	case VCard4:
+		// tel URI不能转义，只能检查
+		for _, p := range c.Phones {
+			if _, err := telNumber(p.Number); err != nil {
+				return "", err
+			}
+		}
+		return c.vcard(true), nil
+	case MeCard:
+		return c.mecard(), nil
+	case ContactAuto:
+		// MeCard没有职位
+		s1 := c.vcard(false)
+		if c.Title != "" {
+			return s1, nil
+		}
+		s2 := c.mecard()
+		if payloadBits(s2) < payloadBits(s1) {
+			return s2, nil
+		}
+		return s1, nil
+	}
+	return "", fmt.Errorf("invalid contact format <%d>", c.Format)
+}
+
+// 全名
+func (c *Contact) fullName() string {
+	if c.FirstName == "" || c.LastName == "" {
+		return c.FirstName + c.LastName
+	}
+	return c.FirstName + " " + c.LastName
+}
+
+// vCard，v4是4.0，否则是3.0
+func (c *Contact) vcard(v4 bool) string {
+	var b strings.Builder
+	line := func(name, value string) {
+		vcardFold(&b, name+":"+value)
+	}
+	typ := func(name, t string) string {
+		if t == "" {
+			return name
+		}
+		if v4 {
+			return name + ";TYPE=" + strings.ToLower(t)
+		}
+		return name + ";TYPE=" + strings.ToUpper(t)
+	}
+	line("BEGIN", "VCARD")
+	if v4 {
+		line("VERSION", "4.0")
+	} else {
+		line("VERSION", "3.0")
+	}
+	line("N", vcardEscape(c.LastName)+";"+vcardEscape(c.FirstName)+";;;")
+	line("FN", vcardEscape(c.fullName()))
+	if c.Organization != "" {
+		line("ORG", vcardEscape(c.Organization))
+	}
+	if c.Title != "" {
+		line("TITLE", vcardEscape(c.Title))
+	}
+	for _, p := range c.Phones {
+		if v4 {
+			// tel URI不能有空格
+			line(typ("TEL", p.Type)+";VALUE=uri", "tel:"+strings.ReplaceAll(p.Number, " ", "-"))
+		} else {
+			line(typ("TEL", p.Type), vcardEscape(p.Number))
+		}
+	}
+	for _, e := range c.Emails {
+		line("EMAIL", vcardEscape(e))
+	}
+	for _, a := range c.Addresses {
+		f := a.fields()
+		for i := range f {
+			f[i] = vcardEscape(f[i])
+		}
+		line(typ("ADR", a.Type), strings.Join(f, ";"))
+	}
+	if c.URL != "" {
+		line("URL", vcardEscape(c.URL))
+	}
+	if c.Note != "" {
+		line("NOTE", vcardEscape(c.Note))
+	}
+	line("END", "VCARD")
+	return b.String()
+}
+
+// MeCard
+func (c *Contact) mecard() string {
+	var b strings.Builder
+	field := func(name, value string) {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(value)
+		b.WriteByte(';')
+	}
+	b.WriteString("MECARD:")
+	if c.FirstName == "" || c.LastName == "" {
+		field("N", mecardEscape(c.fullName()))
+	} else {
+		field("N", mecardEscape(c.LastName)+","+mecardEscape(c.FirstName))
+	}
+	if c.Organization != "" {
+		field("ORG", mecardEscape(c.Organization))
+	}
+	for _, p := range c.Phones {
+		field("TEL", mecardEscape(p.Number))
+	}
+	for _, e := range c.Emails {
+		field("EMAIL", mecardEscape(e))
+	}
+	for _, a := range c.Addresses {
+		f := a.fields()
+		for i := range f {
+			f[i] = mecardEscape(f[i])
+		}
+		field("ADR", strings.Join(f, ","))
+	}
+	if c.URL != "" {
+		field("URL", mecardEscape(c.URL))
+	}
+	if c.Note != "" {
+		field("NOTE", mecardEscape(c.Note))
+	}
+	b.WriteByte(';')
+	return b.String()
+}
+
+// 转义'\'，','，';'和换行
+func vcardEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `,`, `\,`, `;`, `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// 写入一行，超过75个字节折行，不拆分UTF-8字符
+func vcardFold(b *strings.Builder, s string) {
+	n := vcardLineLength
+	for len(s) > n {
+		i := n
+		for i > 0 && !utf8.RuneStart(s[i]) {
+			i--
+		}
+		b.WriteString(s[:i])
+		b.WriteString("\r\n ")
+		s = s[i:]
+		// 后面的行以空格开头
+		n = vcardLineLength - 1
+	}
+	b.WriteString(s)
+	b.WriteString("\r\n")
+}
+
+// 按照编码模式估算的数据bit数，用于比较不同格式的大小
+func payloadBits(s string) int {
+	switch analysisMode(s) {
+	case numericMode:
+		return (len(s)*10 + 2) / 3
+	case alphanumericMode:
+		return (len(s)*11 + 1) / 2
+	case kanJiMode:
+		return utf8.RuneCountInString(s) * 13
+	}
+	return len(s) * 8
+}
+
+// 解析vCard或者MeCard
+func ParseContact(str string) (*Contact, error) {
+	if hasPrefixFold(str, "MECARD:") {
+		return parseMeCard(str[len("MECARD:"):])
+	}
+	if hasPrefixFold(str, "BEGIN:VCARD") {
+		return parseVCard(str)
+	}
+	return nil, fmt.Errorf("invalid contact prefix")
+}
+
+// 解析MeCard，不包括"MECARD:"
+func parseMeCard(str string) (*Contact, error) {
+	fields, err := mecardFields(str)
+	if err != nil {
+		return nil, err
+	}
+	c := &Contact{Format: MeCard}
+	for _, f := range fields {
+		value := mecardUnescape(f[1])
+		switch strings.ToUpper(f[0]) {
+		case "N":
+			// 姓,名
+			if parts := splitUnescaped(f[1], ','); len(parts) > 1 {
+				c.LastName, c.FirstName = mecardUnescape(parts[0]), mecardUnescape(parts[1])
+			} else {
+				c.FirstName = value
+			}
+		case "ORG":
+			c.Organization = value
+		case "TEL":
+			c.Phones = append(c.Phones, Phone{Number: value})
+		case "EMAIL":
+			c.Emails = append(c.Emails, value)
+		case "ADR":
+			// 7个部分用','分开，否则都作为街道
+			parts := splitUnescaped(f[1], ',')
+			if len(parts) != 7 {
+				c.Addresses = append(c.Addresses, Address{Street: value})
+				break
+			}
+			for i := range parts {
+				parts[i] = mecardUnescape(parts[i])
+			}
+			c.Addresses = append(c.Addresses, Address{
+				POBox: parts[0], Extended: parts[1], Street: parts[2], City: parts[3],
+				Region: parts[4], PostalCode: parts[5], Country: parts[6],
+			})
+		case "URL":
+			c.URL = value
+		case "NOTE":
+			c.Note = value
+		}
+	}
+	if c.FirstName == "" && c.LastName == "" {
+		return nil, fmt.Errorf("contact name is empty")
+	}
+	return c, nil
+}
+
+// 解析vCard
+func parseVCard(str string) (*Contact, error) {
+	c := &Contact{Format: VCard3}
+	fn := ""
+	end := false
+	for _, line := range unfoldLines(str) {
+		if line == "" {
+			continue
+		}
+		i := indexUnescaped(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid vcard line <%s>", line)
+		}
+		// 属性名和参数
+		params := strings.Split(line[:i], ";")
+		name := strings.ToUpper(params[0])
+		// 去掉分组前缀，例如item1.TEL
+		if k := strings.LastIndexByte(name, '.'); k >= 0 {
+			name = name[k+1:]
+		}
+		typ := ""
+		for _, p := range params[1:] {
+			p = strings.ToLower(p)
+			if strings.HasPrefix(p, "type=") {
+				p = p[len("type="):]
+			} else if strings.Contains(p, "=") {
+				continue
+			}
+			// 多个类型只取第一个
+			if typ == "" {
+				typ = strings.SplitN(p, ",", 2)[0]
+			}
+		}
+		value := line[i+1:]
+		switch name {
+		case "VERSION":
+			if value == "4.0" {
+				c.Format = VCard4
+			}
+		case "N":
+			parts := splitUnescaped(value, ';')
+			c.LastName = vcardUnescape(parts[0])
+			if len(parts) > 1 {
+				c.FirstName = vcardUnescape(parts[1])
+			}
+		case "FN":
+			fn = vcardUnescape(value)
+		case "ORG":
+			c.Organization = vcardUnescape(strings.Join(splitUnescaped(value, ';'), " "))
+		case "TITLE":
+			c.Title = vcardUnescape(value)
+		case "TEL":
+			c.Phones = append(c.Phones, Phone{Type: typ, Number: strings.TrimPrefix(vcardUnescape(value), "tel:")})
+		case "EMAIL":
+			c.Emails = append(c.Emails, vcardUnescape(value))
+		case "ADR":
+			parts := splitUnescaped(value, ';')
+			for len(parts) < 7 {
+				parts = append(parts, "")
+			}
+			for k := range parts {
+				parts[k] = vcardUnescape(parts[k])
+			}
+			c.Addresses = append(c.Addresses, Address{
+				Type: typ, POBox: parts[0], Extended: parts[1], Street: parts[2], City: parts[3],
+				Region: parts[4], PostalCode: parts[5], Country: parts[6],
+			})
+		case "URL":
+			c.URL = vcardUnescape(value)
+		case "NOTE":
+			c.Note = vcardUnescape(value)
+		case "END":
+			end = true
+		}
+	}
+	if !end {
+		return nil, fmt.Errorf("vcard END not found")
+	}
+	// 没有N使用FN
+	if c.FirstName == "" && c.LastName == "" {
+		c.FirstName = fn
+	}
+	if c.FirstName == "" && c.LastName == "" {
+		return nil, fmt.Errorf("contact name is empty")
+	}
+	return c, nil
+}
+
+// 拆分行，合并以空格或者制表符开头的折行
+func unfoldLines(str string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(str, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && line != "" && (line[0] == ' ' || line[0] == '\t') {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// 去掉转义，"\n"是换行
+func vcardUnescape(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' || s[i] == 'N' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// 忽略大小写判断前缀
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
@@ -0,0 +1,69 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestContact(t *testing.T) {
+	c := &Contact{
+		FirstName: "Ann;a",
+		LastName:  "O,Neil",
+		Title:     `R\D`,
+		Phones:    []Phone{{Type: "cell", Number: "+1 555 0100"}},
+		Note:      "line1\nline2",
+	}
+	for _, f := range []struct {
+		format ContactFormat
+		want   string
+	}{
+		{VCard3, "BEGIN:VCARD\r\nVERSION:3.0\r\nN:O\\,Neil;Ann\\;a;;;\r\nFN:Ann\\;a O\\,Neil\r\nTITLE:R\\\\D\r\n" +
+			"TEL;TYPE=CELL:+1 555 0100\r\nNOTE:line1\\nline2\r\nEND:VCARD\r\n"},
+		{VCard4, "BEGIN:VCARD\r\nVERSION:4.0\r\nN:O\\,Neil;Ann\\;a;;;\r\nFN:Ann\\;a O\\,Neil\r\nTITLE:R\\\\D\r\n" +
+			"TEL;TYPE=cell;VALUE=uri:tel:+1-555-0100\r\nNOTE:line1\\nline2\r\nEND:VCARD\r\n"},
+		{MeCard, "MECARD:N:O\\,Neil,Ann\\;a;TEL:+1 555 0100;NOTE:line1\nline2;;"},
+	} {
+		c.Format = f.format
+		s, err := c.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != f.want {
+			t.Fatalf("format %d got <%q>", f.format, s)
+		}
+	}
+	// vCard 4.0的tel URI不能注入
+	for _, n := range []string{"1;x\nEND:VCARD", "1,2", "1\r\n2", "abc", ""} {
+		c := &Contact{Format: VCard4, FirstName: "A", Phones: []Phone{{Number: n}}}
+		if _, err := c.Text(); err == nil {
+			t.Fatalf("number <%q> expect error", n)
+		}
+	}
+	// 折行，每行不超过75个字节，不拆分UTF-8字符
+	c = &Contact{Format: VCard3, FirstName: "A", Note: strings.Repeat("é", 100)}
+	s, err := c.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(s, "\r\n"), "\r\n")
+	note := ""
+	for _, l := range lines {
+		if len(l) > vcardLineLength || !utf8.ValidString(l) {
+			t.Fatalf("invalid line <%s>", l)
+		}
+		if strings.HasPrefix(l, "NOTE:") {
+			note = l
+		} else if strings.HasPrefix(l, " ") {
+			note += l[1:]
+		}
+	}
+	if note != "NOTE:"+strings.Repeat("é", 100) {
+		t.Fatalf("unfold got <%s>", note)
+	}
+	// ContactAuto选择较短的MeCard
+	c = &Contact{Format: ContactAuto, FirstName: "A", LastName: "B"}
+	if s, _ = c.Text(); !strings.HasPrefix(s, "MECARD:") {
+		t.Fatalf("auto got <%s>", s)
+	}
+}
@@ -1,5 +1,14 @@
 package qrcode
 
+import (
+	"sync"
+)
+
+const (
+	// 每个块最多的纠错码个数
+	maxECBytes = 30
+)
+
 var (
 	// galois对数表
 	galoisLogTable = []byte{
@@ -59,6 +68,13 @@ var (
 		44, 88, 176, 125, 250, 233, 207, 131, 27, 54,
 		108, 216, 173, 71, 142, 1,
 	}
+	// galois指数表的两倍长度，两个对数相加不需要取模
+	galoisExpDoubleTable [510]byte
+	// 生成多项式的缓存，下标是纠错码的个数
+	genPolyTable [maxECBytes + 1]struct {
+		once sync.Once
+		poly []byte
+	}
 	// 纠错表
 	errorCorrectionTable = [maxVersion][maxLevel]*errorCorrection{
 		{
@@ -304,6 +320,17 @@ var (
 	}
 )
 
+func init() {
+	initGaloisExpDoubleTable()
+}
+
+// 初始化galois指数表
+func initGaloisExpDoubleTable() {
+	for i := range galoisExpDoubleTable {
+		galoisExpDoubleTable[i] = galoisExpTable[i%255]
+	}
+}
+
 // 纠错表
 type errorCorrection struct {
 	TotalBytes       int // Total Number of Data Codewords for this Version and EC Level
@@ -315,125 +342,103 @@ type errorCorrection struct {
 }
 
 type eccEncoder struct {
-	buff *buffer  // 共享的缓存
-	poly []byte   // 生成的多项式
-	data []byte   // 编码后的数据
-	xy   [][]byte // 二维表，交错使用
+	buff *buffer // 共享的缓存
+	poly []byte  // 生成多项式，第一项是1，只保存其他项系数的对数
+	data []byte  // 编码后的数据，交错之后的数据码字和纠错码字
 }
 
-// 对b进行编码，并返回编码后的数据
+// 对b进行编码，并返回编码后的数据。
+// 余数bit由版本的放置顺序决定，不在data中，见layout
 func (e *eccEncoder) Encode(data []byte, version version, level Level) {
 	// 纠错表
 	ec := errorCorrectionTable[version][level]
+	blocks := ec.Group1Block + ec.Group2Block
 	// 生成多项式
-	e.genPoly(ec.BlockECBytes)
-	// 编码
+	e.poly = genPoly(ec.BlockECBytes)
+	// 编码，所有块的数据，然后是所有块的纠错码
 	e.data = e.data[:0]
 	e.data = append(e.data, data...)
-	p := data
-	for i := 0; i < ec.Group1Block; i++ {
-		e.data = append(e.data, e.encode(p[:ec.Group1BlockBytes])...)
-		p = p[ec.Group1BlockBytes:]
+	for y := 0; y < blocks; y++ {
+		n := e.blockBytes(ec, y)
+		e.data = append(e.data, e.encode(data[:n])...)
+		data = data[n:]
 	}
-	for i := 0; i < ec.Group2Block; i++ {
-		e.data = append(e.data, e.encode(p[:ec.Group2BlockBytes])...)
-		p = p[ec.Group2BlockBytes:]
-	}
-	// 交错
-	if ec.Group2Block > 0 || ec.Group1Block > 1 {
-		e.buff.Resize(len(e.data), -1)
-		// 二维表
-		e.xy = e.xy[:0]
-		p = e.data
-		for i := 0; i < ec.Group1Block; i++ {
-			e.xy = append(e.xy, p[:ec.Group1BlockBytes])
-			p = p[ec.Group1BlockBytes:]
-		}
-		for i := 0; i < ec.Group2Block; i++ {
-			e.xy = append(e.xy, p[:ec.Group2BlockBytes])
-			p = p[ec.Group2BlockBytes:]
-		}
-		idx := 0
-		// 数据交错
-		for x := 0; x < ec.Group1BlockBytes; x++ {
-			for y := 0; y < len(e.xy); y++ {
-				e.buff.data[idx] = e.xy[y][x]
-				idx++
-			}
-		}
-		if ec.Group2BlockBytes > ec.Group1BlockBytes {
-			for y := 0; y < ec.Group2Block; y++ {
-				e.buff.data[idx] = e.xy[y+ec.Group1Block][ec.Group1BlockBytes]
+	// 交错，每次取每个块的第x个字节，第二组的块多1个字节
+	e.buff.Resize(len(e.data), -1)
+	idx := 0
+	for x := 0; x < ec.Group1BlockBytes || x < ec.Group2BlockBytes; x++ {
+		p := 0
+		for y := 0; y < blocks; y++ {
+			n := e.blockBytes(ec, y)
+			if x < n {
+				e.buff.data[idx] = e.data[p+x]
 				idx++
 			}
+			p += n
 		}
-		// 纠错码交错
-		e.xy = e.xy[:0]
-		p = e.data[idx:]
-		for i := 0; i < (ec.Group1Block + ec.Group2Block); i++ {
-			e.xy = append(e.xy, p[:ec.BlockECBytes])
-			p = p[ec.BlockECBytes:]
-		}
-		for x := 0; x < ec.BlockECBytes; x++ {
-			for y := 0; y < len(e.xy); y++ {
-				e.buff.data[idx] = e.xy[y][x]
-				idx++
-			}
-		}
-		// 添加余数
-		if interleaveRemainder[level] > 0 {
-			e.buff.data = append(e.buff.data, 0)
+	}
+	// 纠错码交错，每个块的纠错码个数相同
+	for x := 0; x < ec.BlockECBytes; x++ {
+		for y := 0; y < blocks; y++ {
+			e.buff.data[idx] = e.data[ec.TotalBytes+y*ec.BlockECBytes+x]
+			idx++
 		}
-		// 交换缓存
-		t := e.buff.data
-		e.buff.data = e.data
-		e.data = t
 	}
+	// 交换缓存
+	e.buff.data, e.data = e.data, e.buff.data
+}
+
+// 第y个块的数据字节个数
+func (e *eccEncoder) blockBytes(ec *errorCorrection, y int) int {
+	if y < ec.Group1Block {
+		return ec.Group1BlockBytes
+	}
+	return ec.Group2BlockBytes
 }
 
-// 编码
+// 编码，多项式除法，余数是纠错码
 func (e *eccEncoder) encode(data []byte) []byte {
-	e.buff.Resize(len(data)+len(e.poly)-1, len(data))
+	e.buff.Resize(len(data)+len(e.poly), len(data))
 	copy(e.buff.data, data)
 	for i := 0; i < len(data); i++ {
 		if e.buff.data[i] != 0 {
-			for j := 1; j < len(e.poly); j++ {
-				e.buff.data[i+j] ^= e.galoisMul(e.poly[j], e.buff.data[i])
+			// 对数相加，不需要取模
+			n := galoisLogTable[e.buff.data[i]]
+			r := e.buff.data[i+1 : i+1+len(e.poly)]
+			for j, p := range e.poly {
+				r[j] ^= galoisExpDoubleTable[int(n)+int(p)]
 			}
 		}
 	}
 	return e.buff.data[len(data):]
 }
 
-// 多项式乘法，p.poly * poly
-func (e *eccEncoder) mulPoly(poly []byte) {
-	e.buff.Resize(len(e.poly)+len(poly)-1, 0)
-	for i := 0; i < len(poly); i++ {
-		for j := 0; j < len(e.poly); j++ {
-			e.buff.data[i+j] ^= e.galoisMul(e.poly[j], poly[i])
+// 返回纠错码个数是n的生成多项式，第一次使用时生成
+func genPoly(n int) []byte {
+	t := &genPolyTable[n]
+	t.once.Do(func() {
+		// (x-a^0)(x-a^1)...(x-a^(n-1))，系数是多项式的对数
+		poly := []byte{1}
+		for i := 0; i < n; i++ {
+			p := make([]byte, len(poly)+1)
+			for j, c := range poly {
+				p[j] ^= c
+				p[j+1] ^= galoisMul(c, galoisExpTable[i])
+			}
+			poly = p
 		}
-	}
-	// 交换缓存
-	t := e.buff.data
-	e.buff.data = e.poly
-	e.poly = t
-}
-
-// 根据n，生成多项式
-func (e *eccEncoder) genPoly(n int) {
-	e.poly = e.poly[:1]
-	e.poly[0] = 1
-	a := []byte{1, 0}
-	for i := 0; i < n; i++ {
-		a[1] = galoisExpTable[i]
-		e.mulPoly(a[:])
-	}
+		t.poly = make([]byte, n)
+		for j := range t.poly {
+			t.poly[j] = galoisLogTable[poly[j+1]]
+		}
+	})
+	return t.poly
 }
 
 // galois两个数乘法
-func (e *eccEncoder) galoisMul(n1, n2 byte) byte {
+func galoisMul(n1, n2 byte) byte {
 	if n1 == 0 || n2 == 0 {
 		return 0
 	}
-	return galoisExpTable[(int(galoisLogTable[n1])+int(galoisLogTable[n2]))%255]
+	return galoisExpDoubleTable[int(galoisLogTable[n1])+int(galoisLogTable[n2])]
 }
@@ -0,0 +1,72 @@
+package qrcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEccEncoder(t *testing.T) {
+	// thonky.com的HELLO WORLD，版本1，LevelM
+	var s strEncoder
+	s.bitD = make([]byte, 1)
+	s.buff = new(buffer)
+	if err := s.Encode("HELLO WORLD", LevelM); err != nil {
+		t.Fatal(err)
+	}
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	if !bytes.Equal(s.bitD, data) {
+		t.Fatalf("data got %v", s.bitD)
+	}
+	ec := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	var e eccEncoder
+	e.buff = new(buffer)
+	e.Encode(data, version1, LevelM)
+	if !bytes.Equal(e.data, append(data, ec...)) {
+		t.Fatalf("got %v", e.data)
+	}
+	// thonky.com的交错示例，版本5，LevelQ，2个组各2个块
+	blocks := [][]byte{
+		{67, 85, 70, 134, 87, 38, 85, 194, 119, 50, 6, 18, 6, 103, 38},
+		{246, 246, 66, 7, 118, 134, 242, 7, 38, 86, 22, 198, 199, 146, 6},
+		{182, 230, 247, 119, 50, 7, 118, 134, 87, 38, 82, 6, 134, 151, 50, 7},
+		{70, 247, 118, 86, 194, 6, 151, 50, 16, 236, 17, 236, 17, 236, 17, 236},
+	}
+	ecs := [][]byte{
+		{213, 199, 11, 45, 115, 247, 241, 223, 229, 248, 154, 117, 154, 111, 86, 161, 111, 39},
+		{87, 204, 96, 60, 202, 182, 124, 157, 200, 134, 27, 129, 209, 17, 163, 163, 120, 133},
+		{148, 116, 177, 212, 76, 133, 75, 242, 238, 76, 195, 230, 189, 10, 108, 240, 192, 141},
+		{235, 159, 5, 173, 24, 147, 59, 33, 106, 40, 255, 172, 82, 2, 131, 32, 178, 236},
+	}
+	data, want := nil, []byte(nil)
+	for _, b := range blocks {
+		data = append(data, b...)
+	}
+	for x := 0; x < 16; x++ {
+		for _, b := range blocks {
+			if x < len(b) {
+				want = append(want, b[x])
+			}
+		}
+	}
+	for x := 0; x < 18; x++ {
+		for _, b := range ecs {
+			want = append(want, b[x])
+		}
+	}
+	e.Encode(data, version5, LevelQ)
+	if !bytes.Equal(e.data, want) {
+		t.Fatalf("got %v", e.data)
+	}
+	// 所有版本和级别，码字加上余数bit刚好填满数据模块
+	for v := version1; v < maxVersion; v++ {
+		for l := LevelL; l < maxLevel; l++ {
+			ec := errorCorrectionTable[v][l]
+			data := make([]byte, ec.TotalBytes)
+			e.Encode(data, v, l)
+			n := len(e.data)*8 + int(interleaveRemainder[v])
+			if n != len(versionLayout(v).order) {
+				t.Fatalf("version %d level %d got %d modules", v+1, l, n)
+			}
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package qrcode
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	emvPayloadFormat        = "00"
+	emvPointOfInitiation    = "01"
+	emvMerchantCategoryCode = "52"
+	emvCurrency             = "53"
+	emvAmount               = "54"
+	emvCountryCode          = "58"
+	emvMerchantName         = "59"
+	emvMerchantCity         = "60"
+	emvPostalCode           = "61"
+	emvAdditionalData       = "62"
+	emvCRC                  = "63"
+)
+
+// EMVCo的数据对象，ID+长度+值
+type EMVField struct {
+	ID     string     // 2位数字
+	Value  string     // 值，模板没有值
+	Fields []EMVField // 模板的子数据对象
+}
+
+// 编码
+func (f *EMVField) encode(b *strings.Builder) error {
+	if !isDigits(f.ID) || len(f.ID) != 2 {
+		return fmt.Errorf("invalid emvco id <%s>", f.ID)
+	}
+	v := f.Value
+	if len(f.Fields) > 0 {
+		var t strings.Builder
+		if err := encodeEMVFields(&t, f.Fields); err != nil {
+			return err
+		}
+		v = t.String()
+	}
+	if v == "" || len(v) > 99 {
+		return fmt.Errorf("invalid emvco field <%s> length <%d>", f.ID, len(v))
+	}
+	fmt.Fprintf(b, "%s%02d%s", f.ID, len(v), v)
+	return nil
+}
+
+// 按ID的顺序编码
+func encodeEMVFields(b *strings.Builder, fields []EMVField) error {
+	fs := append([]EMVField(nil), fields...)
+	sort.SliceStable(fs, func(i, j int) bool { return fs[i].ID < fs[j].ID })
+	for i := range fs {
+		if err := fs[i].encode(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 解析数据对象，template判断ID是否模板
+func parseEMVFields(s string, template func(id string) bool) ([]EMVField, error) {
+	var fs []EMVField
+	for s != "" {
+		if len(s) < 4 || !isDigits(s[:4]) {
+			return nil, fmt.Errorf("invalid emvco field <%s>", s)
+		}
+		n, _ := strconv.Atoi(s[2:4])
+		if len(s) < 4+n {
+			return nil, fmt.Errorf("emvco field <%s> length <%d> out of range", s[:2], n)
+		}
+		f := EMVField{ID: s[:2], Value: s[4 : 4+n]}
+		if template != nil && template(f.ID) {
+			var err error
+			f.Fields, err = parseEMVFields(f.Value, nil)
+			if err != nil {
+				return nil, err
+			}
+			f.Value = ""
+		}
+		fs = append(fs, f)
+		s = s[4+n:]
+	}
+	return fs, nil
+}
+
+// 是否模板
+func isEMVTemplate(id string) bool {
+	return (id >= "26" && id <= "51") || id == emvAdditionalData || id == "64" || id >= "80"
+}
+
+// EMVCo商户主扫码
+type EMVCo struct {
+	PointOfInitiation    string     // 01，静态码是"11"，动态码是"12"，可以为空
+	MerchantAccounts     []EMVField // 02-51，商户账户，26-51是模板，子数据对象00是GUID
+	MerchantCategoryCode string     // 52，商户类别码，4位数字
+	Currency             string     // 53，ISO 4217货币数字代码，3位数字
+	Amount               string     // 54，金额，可以为空
+	CountryCode          string     // 58，ISO 3166-1国家代码，2个字母
+	MerchantName         string     // 59，商户名称，最多25个字符
+	MerchantCity         string     // 60，商户城市，最多15个字符
+	PostalCode           string     // 61，邮编，可以为空
+	AdditionalData       []EMVField // 62，附加数据模板的子数据对象，例如01账单号，05参考标签
+	Others               []EMVField // 其他数据对象，例如55-57小费，64语言模板，80-99自定义模板
+}
+
+// 检查字段
+func (e *EMVCo) validate() error {
+	if e.PointOfInitiation != "" && e.PointOfInitiation != "11" && e.PointOfInitiation != "12" {
+		return fmt.Errorf("invalid emvco point of initiation <%s>", e.PointOfInitiation)
+	}
+	if len(e.MerchantAccounts) == 0 {
+		return fmt.Errorf("emvco merchant account is empty")
+	}
+	for _, f := range e.MerchantAccounts {
+		if f.ID < "02" || f.ID > "51" {
+			return fmt.Errorf("invalid emvco merchant account id <%s>", f.ID)
+		}
+		if f.ID >= "26" && len(f.Fields) == 0 {
+			return fmt.Errorf("emvco merchant account <%s> must be a template", f.ID)
+		}
+	}
+	if len(e.MerchantCategoryCode) != 4 || !isDigits(e.MerchantCategoryCode) {
+		return fmt.Errorf("invalid emvco merchant category code <%s>", e.MerchantCategoryCode)
+	}
+	if len(e.Currency) != 3 || !isDigits(e.Currency) {
+		return fmt.Errorf("invalid emvco currency <%s>", e.Currency)
+	}
+	if e.Amount != "" {
+		if len(e.Amount) > 13 || strings.Count(e.Amount, ".") > 1 || !isDigits(strings.Replace(e.Amount, ".", "", 1)) {
+			return fmt.Errorf("invalid emvco amount <%s>", e.Amount)
+		}
+	}
+	if len(e.CountryCode) != 2 || !isUpperLetters(e.CountryCode) {
+		return fmt.Errorf("invalid emvco country code <%s>", e.CountryCode)
+	}
+	if e.MerchantName == "" || len(e.MerchantName) > 25 {
+		return fmt.Errorf("invalid emvco merchant name <%s>", e.MerchantName)
+	}
+	if e.MerchantCity == "" || len(e.MerchantCity) > 15 {
+		return fmt.Errorf("invalid emvco merchant city <%s>", e.MerchantCity)
+	}
+	if len(e.PostalCode) > 10 {
+		return fmt.Errorf("invalid emvco postal code <%s>", e.PostalCode)
+	}
+	for _, f := range e.Others {
+		if f.ID <= emvAmount || (f.ID >= emvCountryCode && f.ID <= emvCRC) {
+			return fmt.Errorf("emvco field <%s> must use the named field", f.ID)
+		}
+	}
+	return nil
+}
+
+// 生成字符串，最后是CRC
+func (e *EMVCo) Text() (string, error) {
+	if err := e.validate(); err != nil {
+		return "", err
+	}
+	fs := []EMVField{{ID: emvPayloadFormat, Value: "01"}}
+	if e.PointOfInitiation != "" {
+		fs = append(fs, EMVField{ID: emvPointOfInitiation, Value: e.PointOfInitiation})
+	}
+	fs = append(fs, e.MerchantAccounts...)
+	fs = append(fs, EMVField{ID: emvMerchantCategoryCode, Value: e.MerchantCategoryCode})
+	fs = append(fs, EMVField{ID: emvCurrency, Value: e.Currency})
+	if e.Amount != "" {
+		fs = append(fs, EMVField{ID: emvAmount, Value: e.Amount})
+	}
+	fs = append(fs, EMVField{ID: emvCountryCode, Value: e.CountryCode})
+	fs = append(fs, EMVField{ID: emvMerchantName, Value: e.MerchantName})
+	fs = append(fs, EMVField{ID: emvMerchantCity, Value: e.MerchantCity})
+	if e.PostalCode != "" {
+		fs = append(fs, EMVField{ID: emvPostalCode, Value: e.PostalCode})
+	}
+	if len(e.AdditionalData) > 0 {
+		fs = append(fs, EMVField{ID: emvAdditionalData, Fields: e.AdditionalData})
+	}
+	fs = append(fs, e.Others...)
+	var b strings.Builder
+	if err := encodeEMVFields(&b, fs); err != nil {
+		return "", err
+	}
+	// CRC包括"6304"
+	b.WriteString(emvCRC + "04")
+	fmt.Fprintf(&b, "%04X", crc16CCITT([]byte(b.String())))
+	return b.String(), nil
+}
+
+// 解析字符串，检查CRC
+func ParseEMVCo(str string) (*EMVCo, error) {
+	if len(str) < 8 || str[len(str)-8:len(str)-4] != emvCRC+"04" {
+		return nil, fmt.Errorf("emvco crc field not found")
+	}
+	crc, err := strconv.ParseUint(str[len(str)-4:], 16, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid emvco crc <%s>", str[len(str)-4:])
+	}
+	if uint16(crc) != crc16CCITT([]byte(str[:len(str)-4])) {
+		return nil, fmt.Errorf("emvco crc <%s> mismatch", str[len(str)-4:])
+	}
+	fs, err := parseEMVFields(str[:len(str)-8], isEMVTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if len(fs) == 0 || fs[0].ID != emvPayloadFormat || fs[0].Value != "01" {
+		return nil, fmt.Errorf("invalid emvco payload format indicator")
+	}
+	e := new(EMVCo)
+	for _, f := range fs[1:] {
+		switch {
+		case f.ID == emvPointOfInitiation:
+			e.PointOfInitiation = f.Value
+		case f.ID >= "02" && f.ID <= "51":
+			e.MerchantAccounts = append(e.MerchantAccounts, f)
+		case f.ID == emvMerchantCategoryCode:
+			e.MerchantCategoryCode = f.Value
+		case f.ID == emvCurrency:
+			e.Currency = f.Value
+		case f.ID == emvAmount:
+			e.Amount = f.Value
+		case f.ID == emvCountryCode:
+			e.CountryCode = f.Value
+		case f.ID == emvMerchantName:
+			e.MerchantName = f.Value
+		case f.ID == emvMerchantCity:
+			e.MerchantCity = f.Value
+		case f.ID == emvPostalCode:
+			e.PostalCode = f.Value
+		case f.ID == emvAdditionalData:
+			e.AdditionalData = f.Fields
+		default:
+			e.Others = append(e.Others, f)
+		}
+	}
+	if err = e.validate(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// CRC-16/CCITT-FALSE，多项式0x1021，初始值0xFFFF
+func crc16CCITT(b []byte) uint16 {
+	crc := uint16(0xffff)
+	for _, c := range b {
+		crc ^= uint16(c) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// 是否都是数字
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// 是否都是大写字母
+func isUpperLetters(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,33 @@
+package qrcode
+
+import (
+	"testing"
+)
+
+func TestEMVCo(t *testing.T) {
+	if crc := crc16CCITT([]byte("123456789")); crc != 0x29B1 {
+		t.Fatalf("crc16 got <%04X>", crc)
+	}
+	// 巴西央行的PIX示例
+	pix := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-426655440000" +
+		"5204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+	e, err := ParseEMVCo(pix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.MerchantName != "Fulano de Tal" || e.Currency != "986" ||
+		e.MerchantAccounts[0].Fields[0].Value != "br.gov.bcb.pix" ||
+		e.AdditionalData[0].Value != "***" {
+		t.Fatalf("unexpected %+v", e)
+	}
+	s, err := e.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != pix {
+		t.Fatalf("text <%s> not equal <%s>", s, pix)
+	}
+	if _, err = ParseEMVCo(pix[:len(pix)-1] + "E"); err == nil {
+		t.Fatal("expect crc error")
+	}
+}
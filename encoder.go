@@ -0,0 +1,51 @@
+package qrcode
+
+import (
+	"image"
+)
+
+// 编码器，保存编码需要的所有缓存，重复使用时不分配内存。
+// 零值可以直接使用，不能复制，也不能在多个goroutine中同时使用
+type Encoder struct {
+	q qrCode
+}
+
+// 准备缓存
+func (e *Encoder) qrCode() *qrCode {
+	if e.q.strEnc.buff == nil {
+		e.q.init()
+	}
+	return &e.q
+}
+
+// 编码str，按照opt画到dst上，opt为nil使用默认值，颜色只能是Solid，渐变使用StyledImage。
+// dst的大小和调色板会被重新设置，dst.Pix和dst.Palette容量足够时不分配内存
+func (e *Encoder) EncodeInto(dst *image.Paletted, str string, opt *Options) error {
+	q := e.qrCode()
+	err := q.EncodeOptions(str, opt)
+	if err != nil {
+		return err
+	}
+	return q.DrawImage(dst, opt)
+}
+
+// 编码str，把模块矩阵（不包括空白边）按行追加到dst，每个模块1个字节，1是黑色，0是白色。
+// opt只使用Level和ParallelMarkVersion，返回追加后的dst和边长，dst容量足够时不分配内存
+func (e *Encoder) AppendMatrix(dst []byte, str string, opt *Options) ([]byte, int, error) {
+	q := e.qrCode()
+	err := q.EncodeOptions(str, opt)
+	if err != nil {
+		return dst, 0, err
+	}
+	size := q.drawMatrix()
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if q.pix.rows.Get(x, y) {
+				dst = append(dst, 1)
+			} else {
+				dst = append(dst, 0)
+			}
+		}
+	}
+	return dst, size, nil
+}
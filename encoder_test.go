@@ -0,0 +1,95 @@
+package qrcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestEncoder(t *testing.T) {
+	var e Encoder
+	var dst image.Paletted
+	var m []byte
+	for _, s := range []string{testStrV40, testStr, testStrV20} {
+		for l := LevelL; l < maxLevel; l++ {
+			opt := &Options{Level: l}
+			img, err := Image(s, l)
+			if err != nil {
+				if e.EncodeInto(&dst, s, opt) == nil {
+					t.Fatal("expect error")
+				}
+				continue
+			}
+			p := img.(*image.Paletted)
+			if err = e.EncodeInto(&dst, s, opt); err != nil {
+				t.Fatal(err)
+			}
+			if dst.Rect != p.Rect || dst.Stride != p.Stride || !bytes.Equal(dst.Pix, p.Pix) {
+				t.Fatalf("EncodeInto differs, level %d, length %d", l, len(s))
+			}
+			var size int
+			m, size, err = e.AppendMatrix(m[:0], s, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for y := 0; y < size; y++ {
+				for x := 0; x < size; x++ {
+					if m[y*size+x] != p.Pix[(y+4)*p.Stride+4+x] {
+						t.Fatalf("AppendMatrix differs at (%d,%d)", x, y)
+					}
+				}
+			}
+		}
+	}
+	// 选项和StyledImage一样
+	navy := color.RGBA{0, 0, 128, 255}
+	for _, opt := range []*Options{
+		{QuietZone: 2, ModuleSize: 3},
+		{Level: LevelH, ModuleSize: 2, Colors: &Colors{Background: color.RGBA{255, 255, 224, 255}, Finder: Solid{navy}}},
+		{Colors: &Colors{Finder: Solid{navy}, Alignment: Solid{navy}, Timing: Solid{color.RGBA{128, 0, 0, 255}}, Data: Solid{color.Black}}},
+		{Colors: &Colors{Data: Solid{color.Gray{0x40}}}},
+	} {
+		if err := e.EncodeInto(&dst, testStrV20, opt); err != nil {
+			t.Fatal(err)
+		}
+		img, err := StyledImage(testStrV20, opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dst.Rect != img.Bounds() {
+			t.Fatalf("got rect %v, want %v", dst.Rect, img.Bounds())
+		}
+		for y := 0; y < dst.Rect.Dy(); y++ {
+			for x := 0; x < dst.Rect.Dx(); x++ {
+				if !sameColor(dst.At(x, y), img.At(x, y)) {
+					t.Fatalf("pixel (%d,%d) differs", x, y)
+				}
+			}
+		}
+	}
+	// 渐变和对比度不够
+	for _, c := range []*Colors{
+		{Data: &LinearGradient{From: color.Black, To: navy}},
+		{Background: color.Black},
+	} {
+		if e.EncodeInto(&dst, testStr, &Options{Colors: c}) == nil {
+			t.Fatal("expect error")
+		}
+	}
+	// 默认选项以后，不能修改共用的调色板
+	e.EncodeInto(&dst, testStr, nil)
+	e.EncodeInto(&dst, testStr, &Options{Colors: &Colors{Data: Solid{navy}}})
+	if !sameColor(_palette[1], color.Black) {
+		t.Fatal("shared palette changed")
+	}
+	// 缓存足够以后不再分配内存
+	opt := &Options{Level: LevelM, QuietZone: 2, ModuleSize: 4, Colors: &Colors{Finder: Solid{navy}}}
+	n := testing.AllocsPerRun(10, func() {
+		e.EncodeInto(&dst, testStr, opt)
+		m, _, _ = e.AppendMatrix(m[:0], testStr, opt)
+	})
+	if n != 0 {
+		t.Fatalf("got %v allocs", n)
+	}
+}
@@ -0,0 +1,144 @@
+package qrcode
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+const (
+	// 加密数据的头部，版本1，不是Base45的字符，也不是签名数据的头部
+	encryptedVersion1 = 0x81
+	// 版本1的PBKDF2-HMAC-SHA256迭代次数，OWASP推荐的值
+	encryptedIterations1 = 600000
+	// 盐的字节数
+	encryptedSaltSize = 16
+)
+
+var (
+	// 解密失败，口令不对或者数据被篡改
+	ErrDecrypt = errors.New("encrypted payload authentication failed")
+)
+
+// AES-256-GCM加密的数据，密钥由口令使用PBKDF2-HMAC-SHA256生成，
+// 格式是1个字节的头部+16个字节的盐+12个字节的nonce+密文，头部和盐是附加数据
+type Encrypted struct {
+	Message    []byte // 原始消息
+	Passphrase string // 口令
+	Base45     bool   // 编码成Base45，使用字母数字模式，否则是字节模式的二进制
+}
+
+func (e *Encrypted) Text() (string, error) {
+	if e.Passphrase == "" {
+		return "", fmt.Errorf("encrypted payload passphrase is empty")
+	}
+	b := make([]byte, 1+encryptedSaltSize, 1+encryptedSaltSize+12+len(e.Message)+16)
+	b[0] = encryptedVersion1
+	if _, err := rand.Read(b[1:]); err != nil {
+		return "", err
+	}
+	aead, err := encryptedAEAD(e.Passphrase, b[1:])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	b = aead.Seal(append(b, nonce...), nonce, e.Message, b)
+	if e.Base45 {
+		return EncodeBase45(b), nil
+	}
+	return string(b), nil
+}
+
+// 解密扫码得到的加密数据，返回原始消息，口令不对或者数据被篡改返回ErrDecrypt。
+// 很多扫码器把字节模式的数据当作Latin-1，返回UTF-8字符串，也可以解密
+func DecryptPayload(data, passphrase string) ([]byte, error) {
+	b := []byte(data)
+	if strings.HasPrefix(data, string(rune(encryptedVersion1))) {
+		var ok bool
+		if b, ok = latin1Bytes(data); !ok {
+			return nil, fmt.Errorf("invalid latin-1 encrypted payload")
+		}
+	}
+	if len(b) == 0 || b[0] != encryptedVersion1 {
+		var err error
+		if b, err = DecodeBase45(data); err != nil {
+			return nil, err
+		}
+		if len(b) == 0 || b[0] != encryptedVersion1 {
+			return nil, fmt.Errorf("unsupported encrypted payload header")
+		}
+	}
+	if len(b) < 1+encryptedSaltSize+12+16 {
+		return nil, fmt.Errorf("encrypted payload length <%d> too small", len(b))
+	}
+	header := b[:1+encryptedSaltSize]
+	aead, err := encryptedAEAD(passphrase, header[1:])
+	if err != nil {
+		return nil, err
+	}
+	nonce := b[len(header) : len(header)+aead.NonceSize()]
+	msg, err := aead.Open(nil, nonce, b[len(header)+len(nonce):], header)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return msg, nil
+}
+
+// UTF-8字符串转换成Latin-1的字节，字符必须小于0x100
+func latin1Bytes(s string) ([]byte, bool) {
+	b := make([]byte, 0, len(s))
+	for _, c := range s {
+		if c > 0xff {
+			return nil, false
+		}
+		b = append(b, byte(c))
+	}
+	return b, true
+}
+
+// 口令和盐生成密钥
+func encryptedAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(pbkdf2([]byte(passphrase), salt, encryptedIterations1, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// RFC 8018的PBKDF2
+func pbkdf2(password, salt []byte, iter, keyLen int, h func() hash.Hash) []byte {
+	prf := hmac.New(h, password)
+	size := prf.Size()
+	key := make([]byte, 0, (keyLen+size-1)/size*size)
+	var i [4]byte
+	u := make([]byte, size)
+	t := make([]byte, size)
+	for block := 1; len(key) < keyLen; block++ {
+		binary.BigEndian.PutUint32(i[:], uint32(block))
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(i[:])
+		u = prf.Sum(u[:0])
+		copy(t, u)
+		for n := 1; n < iter; n++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
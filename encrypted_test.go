@@ -0,0 +1,52 @@
+package qrcode
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncrypted(t *testing.T) {
+	// RFC 6070的示例
+	if k := hex.EncodeToString(pbkdf2([]byte("password"), []byte("salt"), 4096, 20, sha1.New)); k != "4b007901b765489abead49d926f721d065a429c1" {
+		t.Fatalf("pbkdf2 got <%s>", k)
+	}
+	msg := []byte("asset:7781;owner:finance;room:3F-12")
+	for _, e := range []*Encrypted{
+		{Message: msg, Passphrase: "correct horse"},
+		{Message: msg, Passphrase: "correct horse", Base45: true},
+	} {
+		str, err := e.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Base45 && analysisMode(str) != alphanumericMode {
+			t.Fatalf("expect alphanumeric mode <%s>", str)
+		}
+		m, err := DecryptPayload(str, e.Passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(m, msg) {
+			t.Fatalf("decrypt got <%s>", m)
+		}
+		if _, err = DecryptPayload(str, "wrong"); err != ErrDecrypt {
+			t.Fatalf("expect ErrDecrypt, got %v", err)
+		}
+		if e.Base45 {
+			continue
+		}
+		// 扫码器当作Latin-1返回的UTF-8字符串
+		r := make([]rune, len(str))
+		for i := 0; i < len(str); i++ {
+			r[i] = rune(str[i])
+		}
+		if m, err = DecryptPayload(string(r), e.Passphrase); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(m, msg) {
+			t.Fatalf("decrypt latin-1 got <%s>", m)
+		}
+	}
+}
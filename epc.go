@@ -0,0 +1,193 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// EPC069-12的数据最多331个字节
+	epcMaxBytes = 331
+)
+
+// EPC069-12 SEPA转账（GiroCode）
+type EPC struct {
+	BIC         string // 收款银行的BIC，8或者11个字符，EEA内可以为空
+	Name        string // 收款人，最多70个字符
+	IBAN        string // 收款账户，可以包含空格
+	Amount      string // 欧元金额，例如"12.30"，范围0.01-999999999.99，可以为空
+	Purpose     string // 用途代码，4个字母，可以为空
+	Reference   string // 结构化汇款信息，例如RF参考号，最多35个字符，与Remittance只能有一个
+	Remittance  string // 非结构化汇款信息，最多140个字符，与Reference只能有一个
+	Information string // 给付款人的信息，最多70个字符
+}
+
+// 生成字符串，版本002，UTF-8编码
+func (e *EPC) Text() (string, error) {
+	// 每个字段一行，不能有换行
+	for _, f := range [][2]string{
+		{"bic", e.BIC}, {"name", e.Name}, {"purpose", e.Purpose},
+		{"reference", e.Reference}, {"remittance", e.Remittance}, {"information", e.Information},
+	} {
+		if strings.ContainsAny(f[1], "\r\n") {
+			return "", fmt.Errorf("epc %s <%q> contains line break", f[0], f[1])
+		}
+	}
+	bic := strings.ToUpper(strings.ReplaceAll(e.BIC, " ", ""))
+	if bic != "" && !validBIC(bic) {
+		return "", fmt.Errorf("invalid epc bic <%s>", e.BIC)
+	}
+	if e.Name == "" || utf8.RuneCountInString(e.Name) > 70 {
+		return "", fmt.Errorf("invalid epc name <%s>", e.Name)
+	}
+	iban := strings.ToUpper(strings.ReplaceAll(e.IBAN, " ", ""))
+	if !validIBAN(iban) {
+		return "", fmt.Errorf("invalid epc iban <%s>", e.IBAN)
+	}
+	amount := ""
+	if e.Amount != "" {
+		if !validEPCAmount(e.Amount) {
+			return "", fmt.Errorf("invalid epc amount <%s>", e.Amount)
+		}
+		amount = "EUR" + e.Amount
+	}
+	if e.Purpose != "" && (len(e.Purpose) != 4 || !isUpperLetters(strings.ToUpper(e.Purpose))) {
+		return "", fmt.Errorf("invalid epc purpose <%s>", e.Purpose)
+	}
+	if e.Reference != "" && e.Remittance != "" {
+		return "", fmt.Errorf("epc reference and remittance can not both be set")
+	}
+	if utf8.RuneCountInString(e.Reference) > 35 {
+		return "", fmt.Errorf("epc reference <%s> too long", e.Reference)
+	}
+	if utf8.RuneCountInString(e.Remittance) > 140 {
+		return "", fmt.Errorf("epc remittance <%s> too long", e.Remittance)
+	}
+	if utf8.RuneCountInString(e.Information) > 70 {
+		return "", fmt.Errorf("epc information <%s> too long", e.Information)
+	}
+	lines := []string{
+		"BCD", "002", "1", "SCT", bic, e.Name, iban, amount,
+		strings.ToUpper(e.Purpose), e.Reference, e.Remittance, e.Information,
+	}
+	// 去掉最后的空行
+	for lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	s := strings.Join(lines, "\n")
+	if len(s) > epcMaxBytes {
+		return "", fmt.Errorf("epc data length <%d> too lager", len(s))
+	}
+	return s, nil
+}
+
+// 生成图像，EPC069-12要求使用LevelM
+func (e *EPC) Image() (image.Image, error) {
+	s, err := e.Text()
+	if err != nil {
+		return nil, err
+	}
+	return Image(s, LevelM)
+}
+
+// 检查BIC，4个字母的银行代码，2个字母的国家代码，2个字符的地区代码，3个字符的分行代码
+func validBIC(s string) bool {
+	if len(s) != 8 && len(s) != 11 {
+		return false
+	}
+	if !isUpperLetters(s[:6]) {
+		return false
+	}
+	for i := 6; i < len(s); i++ {
+		if !isUpperAlnum(s[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// 检查IBAN，前4个字符移到最后，除以97余数是1
+func validIBAN(s string) bool {
+	if len(s) < 15 || len(s) > 34 || !isUpperLetters(s[:2]) || !isDigits(s[2:4]) {
+		return false
+	}
+	return mod97(s[4:]+s[:4]) == 1
+}
+
+// 字母转成数字（A=10）后除以97的余数，有其他字符返回-1
+func mod97(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			n = (n*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			n = (n*100 + int(c-'A') + 10) % 97
+		default:
+			return -1
+		}
+	}
+	return n
+}
+
+// 检查金额，最多2位小数，范围0.01-999999999.99
+func validEPCAmount(s string) bool {
+	i := strings.IndexByte(s, '.')
+	integer, fraction := s, ""
+	if i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+		if len(fraction) == 0 || len(fraction) > 2 || !isDigits(fraction) {
+			return false
+		}
+	}
+	if len(integer) == 0 || len(integer) > 9 || !isDigits(integer) {
+		return false
+	}
+	return strings.Trim(integer+fraction, "0") != ""
+}
+
+// 是否大写字母或者数字
+func isUpperAlnum(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// 解析EPC069-12字符串，只支持UTF-8编码
+func ParseEPC(str string) (*EPC, error) {
+	lines := strings.Split(strings.ReplaceAll(str, "\r\n", "\n"), "\n")
+	if len(lines) < 7 || lines[0] != "BCD" {
+		return nil, fmt.Errorf("invalid epc header")
+	}
+	if lines[1] != "001" && lines[1] != "002" {
+		return nil, fmt.Errorf("unsupported epc version <%s>", lines[1])
+	}
+	if lines[2] != "1" {
+		return nil, fmt.Errorf("unsupported epc character set <%s>", lines[2])
+	}
+	if lines[3] != "SCT" {
+		return nil, fmt.Errorf("invalid epc identification <%s>", lines[3])
+	}
+	for len(lines) < 12 {
+		lines = append(lines, "")
+	}
+	e := &EPC{
+		BIC:         lines[4],
+		Name:        lines[5],
+		IBAN:        lines[6],
+		Amount:      strings.TrimPrefix(lines[7], "EUR"),
+		Purpose:     lines[8],
+		Reference:   lines[9],
+		Remittance:  lines[10],
+		Information: lines[11],
+	}
+	// 版本001必须有BIC
+	if lines[1] == "001" && e.BIC == "" {
+		return nil, fmt.Errorf("epc version 001 requires bic")
+	}
+	if _, err := e.Text(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
@@ -0,0 +1,50 @@
+package qrcode
+
+import (
+	"testing"
+)
+
+func TestEPC(t *testing.T) {
+	e := &EPC{
+		BIC:        "COBADEFFXXX",
+		Name:       "Max Mustermann",
+		IBAN:       "DE89 3704 0044 0532 0130 00",
+		Amount:     "12.3",
+		Remittance: "Invoice 42",
+	}
+	s, err := e.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "BCD\n002\n1\nSCT\nCOBADEFFXXX\nMax Mustermann\nDE89370400440532013000\nEUR12.3\n\n\nInvoice 42" {
+		t.Fatalf("unexpected epc text <%q>", s)
+	}
+	e.IBAN = "DE88 3704 0044 0532 0130 00"
+	if _, err = e.Text(); err == nil {
+		t.Fatal("expect iban checksum error")
+	}
+	e.IBAN = "DE89 3704 0044 0532 0130 00"
+	e.Amount = "0.00"
+	if _, err = e.Text(); err == nil {
+		t.Fatal("expect amount error")
+	}
+	// 换行会改变后面字段的位置
+	e.Amount = "12.3"
+	for _, f := range []*string{&e.BIC, &e.Name, &e.Purpose, &e.Remittance, &e.Information} {
+		old := *f
+		*f = "Evil\nDE89370400440532013000"
+		if _, err = e.Text(); err == nil {
+			t.Fatalf("<%q> expect error", *f)
+		}
+		*f = "x\ry"
+		if _, err = e.Text(); err == nil {
+			t.Fatalf("<%q> expect error", *f)
+		}
+		*f = old
+	}
+	e.Remittance = ""
+	e.Reference = "RF18\n539007547034"
+	if _, err = e.Text(); err == nil {
+		t.Fatal("reference expect error")
+	}
+}
@@ -0,0 +1,90 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// EPS选项
+type EPSOptions struct {
+	Options              // 共用选项，ModuleSize是每个模块的点数
+	CMYK      bool       // 使用CMYK颜色，默认是RGB
+	SpotColor *SpotColor // 专色，不为nil时所有黑色模块都使用专色，忽略Colors的填充
+}
+
+// 专色
+type SpotColor struct {
+	Name       string  // 名称，例如"PANTONE 286 C"
+	C, M, Y, K float64 // 不支持专色时的替代颜色，范围[0,1]
+	Tint       float64 // 色调，范围(0,1]，0使用1
+}
+
+// 输出EPS，相邻的黑色模块合并成一个矩形
+func EPS(w io.Writer, str string, opt *EPSOptions) error {
+	if opt == nil {
+		opt = new(EPSOptions)
+	}
+	m, err := newMatrix(str, &opt.Options)
+	if err != nil {
+		return err
+	}
+	c := opt.colors()
+	qz, ms := opt.quietZone(), opt.moduleSize()
+	n := (m.size + qz*2) * ms
+	var buf bytes.Buffer
+	buf.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(&buf, "%%%%BoundingBox: 0 0 %d %d\n", n, n)
+	buf.WriteString("%%Creator: github.com/qq51529210/qrcode\n")
+	buf.WriteString("%%EndComments\n")
+	buf.WriteString("gsave\n")
+	// 背景
+	if c != nil && c.Background != nil {
+		fmt.Fprintf(&buf, "%s 0 0 %d %d rectfill\n", opt.epsColor(c.Background), n, n)
+	}
+	// 坐标变换，原点在左上角，单位是模块
+	fmt.Fprintf(&buf, "0 %d translate %d %d scale\n", n, ms, -ms)
+	buf.WriteString("/r { 1 rectfill } bind def\n")
+	if s := opt.SpotColor; s != nil {
+		tint := s.Tint
+		if tint <= 0 || tint > 1 {
+			tint = 1
+		}
+		fmt.Fprintf(&buf, "[/Separation (%s) /DeviceCMYK {dup %s mul exch dup %s mul exch dup %s mul exch %s mul}] setcolorspace %s setcolor\n",
+			epsEscape(s.Name), pdfNum(s.C), pdfNum(s.M), pdfNum(s.Y), pdfNum(s.K), pdfNum(tint))
+	}
+	var last color.Color
+	err = m.colorRuns(c, func(x, y, n int, fg color.Color) {
+		if opt.SpotColor == nil && (last == nil || !sameColor(fg, last)) {
+			fmt.Fprintf(&buf, "%s\n", opt.epsColor(fg))
+			last = fg
+		}
+		fmt.Fprintf(&buf, "%d %d %d r\n", x+qz, y+qz, n)
+	})
+	if err != nil {
+		return err
+	}
+	buf.WriteString("grestore\n")
+	buf.WriteString("showpage\n")
+	buf.WriteString("%%EOF\n")
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// 设置颜色的命令
+func (o *EPSOptions) epsColor(c color.Color) string {
+	if o.CMYK {
+		k := color.CMYKModel.Convert(c).(color.CMYK)
+		return fmt.Sprintf("%s %s %s %s setcmykcolor",
+			pdfNum(float64(k.C)/0xff), pdfNum(float64(k.M)/0xff),
+			pdfNum(float64(k.Y)/0xff), pdfNum(float64(k.K)/0xff))
+	}
+	return pdfColor(c) + " setrgbcolor"
+}
+
+// 字符串转义
+func epsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`).Replace(s)
+}
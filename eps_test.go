@@ -0,0 +1,57 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEPS(t *testing.T) {
+	str := "HELLO WORLD"
+	m, err := newMatrix(str, &Options{Level: LevelM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.size != 21 {
+		t.Fatalf("size got %d", m.size)
+	}
+	var out bytes.Buffer
+	err = EPS(&out, str, &EPSOptions{Options: Options{Level: LevelM, ModuleSize: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := out.String()
+	if !strings.HasPrefix(s, "%!PS-Adobe-3.0 EPSF-3.0\n%%BoundingBox: 0 0 87 87\n") {
+		t.Fatalf("invalid header <%.60s>", s)
+	}
+	if !strings.Contains(s, "0 87 translate 3 -3 scale\n") || !strings.HasSuffix(s, "%%EOF\n") {
+		t.Fatal("invalid structure")
+	}
+	// 矩形覆盖的模块和矩阵相同
+	pix := make([]uint8, len(m.pix))
+	for i := range pix {
+		pix[i] = _paletteWhite
+	}
+	for _, line := range strings.Split(s, "\n") {
+		var x, y, n int
+		if _, err := fmt.Sscanf(line, "%d %d %d r", &x, &y, &n); err != nil || !strings.HasSuffix(line, " r") {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			pix[(y-4)*m.size+x-4+i] = _paletteBlack
+		}
+	}
+	if !bytes.Equal(pix, m.pix) {
+		t.Fatal("modules differ")
+	}
+	// 专色
+	out.Reset()
+	err = EPS(&out, str, &EPSOptions{SpotColor: &SpotColor{Name: "PANTONE (286) C", C: 1, M: 0.66}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "[/Separation (PANTONE \\(286\\) C) /DeviceCMYK {dup 1 mul exch dup 0.66 mul exch dup 0 mul exch 0 mul}] setcolorspace 1 setcolor\n") {
+		t.Fatal("invalid spot color")
+	}
+}
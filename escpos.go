@@ -0,0 +1,92 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// 对齐方式
+type Align byte
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// ESC/POS选项
+type ESCPOSOptions struct {
+	Options       // 共用选项，ModuleSize是每个模块的点数，GS ( k命令最大是16
+	Native  bool  // 使用GS ( k命令由打印机生成二维码，否则输出GS v 0位图
+	Align   Align // 对齐方式
+}
+
+// 输出ESC/POS命令
+func ESCPOS(w io.Writer, str string, opt *ESCPOSOptions) error {
+	if opt == nil {
+		opt = new(ESCPOSOptions)
+	}
+	if opt.Align > AlignRight {
+		return fmt.Errorf("invalid align <%d>", opt.Align)
+	}
+	var buf bytes.Buffer
+	// ESC a n，对齐
+	buf.Write([]byte{0x1b, 'a', byte(opt.Align)})
+	var err error
+	if opt.Native {
+		err = opt.writeQR(&buf, str)
+	} else {
+		err = opt.writeRaster(&buf, str)
+	}
+	if err != nil {
+		return err
+	}
+	// 换行，恢复左对齐
+	buf.Write([]byte{'\n', 0x1b, 'a', 0})
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// GS ( k命令
+func (o *ESCPOSOptions) writeQR(buf *bytes.Buffer, str string) error {
+	level := o.level()
+	// 检查长度
+	_, err := analysisVersion(str, level, analysisMode(str))
+	if err != nil {
+		return err
+	}
+	size := o.moduleSize()
+	if size > 16 {
+		size = 16
+	}
+	// fn 165，model 2
+	buf.Write([]byte{0x1d, '(', 'k', 4, 0, 49, 65, 50, 0})
+	// fn 167，模块大小
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 67, byte(size)})
+	// fn 169，纠错等级
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 69, byte(48 + level)})
+	// fn 180，保存数据
+	n := len(str) + 3
+	buf.Write([]byte{0x1d, '(', 'k', byte(n), byte(n >> 8), 49, 80, 48})
+	buf.WriteString(str)
+	// fn 181，打印
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, 49, 81, 48})
+	return nil
+}
+
+// GS v 0位图
+func (o *ESCPOSOptions) writeRaster(buf *bytes.Buffer, str string) error {
+	m, err := newMatrix(str, &o.Options)
+	if err != nil {
+		return err
+	}
+	bits, stride := m.bitmap(o.quietZone(), o.moduleSize())
+	rows := len(bits) / stride
+	if stride > 0xffff || rows > 0xffff {
+		return fmt.Errorf("raster image <%dx%d> too large", stride*8, rows)
+	}
+	buf.Write([]byte{0x1d, 'v', '0', 0, byte(stride), byte(stride >> 8), byte(rows), byte(rows >> 8)})
+	buf.Write(bits)
+	return nil
+}
@@ -0,0 +1,56 @@
+package qrcode
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestESCPOS(t *testing.T) {
+	var out bytes.Buffer
+	err := ESCPOS(&out, "hello", &ESCPOSOptions{
+		Options: Options{Level: LevelH, ModuleSize: 20},
+		Native:  true,
+		Align:   AlignCenter,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x1b, 'a', 1,
+		0x1d, '(', 'k', 4, 0, 49, 65, 50, 0,
+		0x1d, '(', 'k', 3, 0, 49, 67, 16,
+		0x1d, '(', 'k', 3, 0, 49, 69, 51,
+		0x1d, '(', 'k', 8, 0, 49, 80, 48, 'h', 'e', 'l', 'l', 'o',
+		0x1d, '(', 'k', 3, 0, 49, 81, 48,
+		'\n', 0x1b, 'a', 0,
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Fatalf("got %v", out.Bytes())
+	}
+	// 数据长度超过255，pL和pH
+	out.Reset()
+	str := strings.Repeat("a", 300)
+	if err = ESCPOS(&out, str, &ESCPOSOptions{Native: true}); err != nil {
+		t.Fatal(err)
+	}
+	store := append([]byte{0x1d, '(', 'k', 0x2f, 0x01, 49, 80, 48}, str...)
+	if !bytes.Contains(out.Bytes(), store) {
+		t.Fatal("invalid store command")
+	}
+	// GS v 0，(21+8)*2=58个点，每行8个字节
+	out.Reset()
+	if err = ESCPOS(&out, "HELLO WORLD", &ESCPOSOptions{Options: Options{Level: LevelM, ModuleSize: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	m, err := newMatrix("HELLO WORLD", &Options{Level: LevelM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bits, _ := m.bitmap(4, 2)
+	want = append([]byte{0x1b, 'a', 0, 0x1d, 'v', '0', 0, 8, 0, 58, 0}, bits...)
+	want = append(want, '\n', 0x1b, 'a', 0)
+	if len(bits) != 8*58 || !bytes.Equal(out.Bytes(), want) {
+		t.Fatalf("invalid raster, %d bytes", out.Len())
+	}
+}
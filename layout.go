@@ -0,0 +1,156 @@
+package qrcode
+
+import (
+	"sync"
+)
+
+const (
+	// 版本信息的bit数
+	versionInfoBits = 18
+)
+
+var (
+	// 每个版本的布局，第一次使用时生成
+	layoutTable [maxVersion]struct {
+		once   sync.Once
+		layout *layout
+	}
+)
+
+// 版本的模块布局，画图，mark和解码都使用
+type layout struct {
+	version            // 版本
+	size    int        // 边长
+	pattern markMatrix // 功能图形，finder，timing，alignment patterns和左下角的黑点
+	data    markMatrix // 可以mark的数据模块
+	order   [][2]uint8 // 数据模块的放置顺序，(x,y)
+	// 版本信息第k个bit（0是最低位）的两个位置，左下角和右上角，版本7以上才有
+	versionInfo [versionInfoBits][2][2]uint8
+}
+
+// 返回版本v的布局
+func versionLayout(v version) *layout {
+	t := &layoutTable[v]
+	t.once.Do(func() {
+		t.layout = newLayout(v)
+	})
+	return t.layout
+}
+
+// 生成版本v的布局
+func newLayout(v version) *layout {
+	l := &layout{version: v, size: qrCodeSizeTable[v]}
+	// 功能图形
+	l.pattern.Reset(l.size)
+	l.drawFinderPatterns()
+	l.drawTimingPatterns()
+	l.drawAlignmentPatterns()
+	l.drawBottomLeftPoint()
+	l.pattern.Transpose()
+	// finder，timing，alignment patterns和格式版本信息不能mark
+	l.data.Reset(l.size)
+	for y := 0; y < l.size; y++ {
+		for x := 0; x < l.size; x++ {
+			if moduleRegion(v, x, y) == RegionData {
+				l.data.rows.Set(x, y, true)
+			}
+		}
+	}
+	l.data.Transpose()
+	// 版本信息，左下角6列3行，右上角3列6行
+	if v >= version7 {
+		for k := range l.versionInfo {
+			i, j := uint8(k/3), uint8(l.size-11+k%3)
+			l.versionInfo[k] = [2][2]uint8{{i, j}, {j, i}}
+		}
+	}
+	// 从右下角开始，每次2列，向上和向下交替，跳过垂直的timing patterns
+	up := true
+	for x := l.size - 1; x > 0; x -= 2 {
+		if x == timingPattern {
+			x--
+		}
+		for i := 0; i < l.size; i++ {
+			y := i
+			if up {
+				y = l.size - 1 - i
+			}
+			for _, c := range [2]int{x, x - 1} {
+				if l.data.rows.Get(c, y) {
+					l.order = append(l.order, [2]uint8{uint8(c), uint8(y)})
+				}
+			}
+		}
+		up = !up
+	}
+	return l
+}
+
+// 画点
+func (l *layout) drawPoint(x, y int, c uint8) {
+	l.pattern.rows.Set(x, y, c == _paletteBlack)
+}
+
+// 画矩形
+func (l *layout) drawRectangle(x1, y1, x2, y2 int, c uint8) {
+	// 上下
+	for x := x1; x <= x2; x++ {
+		l.drawPoint(x, y1, c)
+		l.drawPoint(x, y2, c)
+	}
+	// 左右
+	for y := y1 + 1; y < y2; y++ {
+		l.drawPoint(x1, y, c)
+		l.drawPoint(x2, y, c)
+	}
+}
+
+// 画矩形
+func (l *layout) drawSolidRectangle(x1, y1, x2, y2 int, c uint8) {
+	for y := y1; y <= y2; y++ {
+		for x := x1; x <= x2; x++ {
+			l.drawPoint(x, y, c)
+		}
+	}
+}
+
+// finder patterns
+func (l *layout) drawFinderPatterns() {
+	// 左上角
+	l.drawRectangle(0, 0, 6, 6, _paletteBlack)
+	l.drawSolidRectangle(2, 2, 4, 4, _paletteBlack)
+	// 右上角
+	l.drawRectangle(l.size-7, 0, l.size-1, 6, _paletteBlack)
+	l.drawSolidRectangle(l.size-5, 2, l.size-3, 4, _paletteBlack)
+	// 左下角
+	l.drawRectangle(0, l.size-7, 6, l.size-1, _paletteBlack)
+	l.drawSolidRectangle(2, l.size-5, 4, l.size-3, _paletteBlack)
+}
+
+// timing patterns
+func (l *layout) drawTimingPatterns() {
+	// 水平
+	for i := 8; i < l.size-8; {
+		l.drawPoint(i, 6, _paletteBlack)
+		i += 2
+	}
+	// 垂直
+	for i := 8; i < l.size-8; {
+		l.drawPoint(6, i, _paletteBlack)
+		i += 2
+	}
+}
+
+// alignment patterns，
+func (l *layout) drawAlignmentPatterns() {
+	for _, r := range alignmentPatternTable[l.version] {
+		l.drawRectangle(r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, _paletteBlack)
+		l.drawPoint(r.Min.X+2, r.Min.Y+2, _paletteBlack)
+	}
+}
+
+// 左下角，格式信息上的一个黑点
+func (l *layout) drawBottomLeftPoint() {
+	// y=version*4+4+9，
+	l.drawPoint(8, int(l.version)*4+13, _paletteBlack)
+}
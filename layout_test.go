@@ -0,0 +1,86 @@
+package qrcode
+
+import (
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	for v := version1; v < maxVersion; v++ {
+		l := versionLayout(v)
+		// ISO/IEC 18004的数据模块个数
+		n := int(v) + 1
+		modules := (16*n+128)*n + 64
+		if n >= 2 {
+			a := n/7 + 2
+			modules -= 25*a*a - 10*a - 55
+		}
+		if n >= 7 {
+			modules -= 36
+		}
+		if len(l.order) != modules {
+			t.Fatalf("version %d data modules got %d, want %d", n, len(l.order), modules)
+		}
+		ec := errorCorrectionTable[v][LevelL]
+		codewords := ec.TotalBytes + ec.BlockECBytes*(ec.Group1Block+ec.Group2Block)
+		if codewords*8+int(interleaveRemainder[v]) != modules {
+			t.Fatalf("version %d codewords %d remainder %d, modules %d", n, codewords, interleaveRemainder[v], modules)
+		}
+		// 放置顺序不重复，都是数据模块
+		seen := make(map[[2]uint8]bool)
+		for _, p := range l.order {
+			if seen[p] || moduleRegion(v, int(p[0]), int(p[1])) != RegionData {
+				t.Fatalf("version %d invalid module %v", n, p)
+			}
+			seen[p] = true
+		}
+	}
+}
+
+func TestVersionInformation(t *testing.T) {
+	// ISO/IEC 18004附录D的示例
+	for _, c := range []struct {
+		v    version
+		code int
+	}{
+		{version7, 0x07C94},
+		{version40, 0x28C69},
+	} {
+		// BCH(18,6)
+		code := int(c.v+1) << 12
+		for i := 17; i >= 12; i-- {
+			if code&(1<<i) != 0 {
+				code ^= 0x1F25 << (i - 12)
+			}
+		}
+		code |= int(c.v+1) << 12
+		if code != c.code {
+			t.Fatalf("version %d code got %05X", c.v+1, code)
+		}
+		l := versionLayout(c.v)
+		var q qrCode
+		var m markMatrix
+		m.Reset(l.size)
+		q.drawVersionInformation(&m, l)
+		// 左下角的(i,size-11+j)和右上角的(size-11+j,i)是第3i+j个bit
+		n := l.size - 11
+		for y := 0; y < l.size; y++ {
+			for x := 0; x < l.size; x++ {
+				want, info := false, true
+				switch {
+				case x < 6 && y >= n && y < n+3:
+					want = code&(1<<(3*x+y-n)) != 0
+				case y < 6 && x >= n && x < n+3:
+					want = code&(1<<(3*y+x-n)) != 0
+				default:
+					info = false
+				}
+				if info && moduleRegion(c.v, x, y) != RegionFormat {
+					t.Fatalf("version %d module (%d,%d) is %s", c.v+1, x, y, moduleRegion(c.v, x, y))
+				}
+				if m.rows.Get(x, y) != want || m.cols.Get(y, x) != want {
+					t.Fatalf("version %d module (%d,%d) want %v", c.v+1, x, y, want)
+				}
+			}
+		}
+	}
+}
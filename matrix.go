@@ -0,0 +1,101 @@
+package qrcode
+
+import (
+	"image/color"
+)
+
+// 模块矩阵，不包括空白边
+type matrix struct {
+	version         // 版本
+	size    int     // 边长
+	pix     []uint8 // 模块，size*size，_paletteBlack或者_paletteWhite
+}
+
+// 按照opt编码str，返回模块矩阵
+func newMatrix(str string, opt *Options) (*matrix, error) {
+	q := _pool.Get().(*qrCode)
+	defer _pool.Put(q)
+	err := q.EncodeOptions(str, opt)
+	if err != nil {
+		return nil, err
+	}
+	m := new(matrix)
+	m.version = q.strEnc.version
+	m.size = q.drawMatrix()
+	m.pix = make([]uint8, m.size*m.size)
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if q.pix.rows.Get(x, y) {
+				m.pix[y*m.size+x] = _paletteBlack
+			} else {
+				m.pix[y*m.size+x] = _paletteWhite
+			}
+		}
+	}
+	return m, nil
+}
+
+// (x,y)是否黑色模块
+func (m *matrix) Dark(x, y int) bool {
+	return m.pix[y*m.size+x] == _paletteBlack
+}
+
+// (x,y)模块所在的区域
+func (m *matrix) Region(x, y int) Region {
+	return moduleRegion(m.version, x, y)
+}
+
+// 按行遍历黑色模块，相邻并且颜色相同的模块合并成一段，
+// (x,y)是第一个模块的位置，n是模块的个数
+func (m *matrix) colorRuns(c *Colors, fn func(x, y, n int, fg color.Color)) error {
+	for y := 0; y < m.size; y++ {
+		x0, n := 0, 0
+		var last color.Color
+		for x := 0; x <= m.size; x++ {
+			var fg color.Color
+			if x < m.size && m.Dark(x, y) {
+				r := m.Region(x, y)
+				fg = c.paint(r).At((float64(x)+0.5)/float64(m.size), (float64(y)+0.5)/float64(m.size))
+				if err := c.checkContrast(r, fg); err != nil {
+					return err
+				}
+				if n > 0 && sameColor(fg, last) {
+					n++
+					continue
+				}
+			}
+			if n > 0 {
+				fn(x0, y, n, last)
+				n = 0
+			}
+			if fg != nil {
+				x0, n, last = x, 1, fg
+			}
+		}
+	}
+	return nil
+}
+
+// 单色位图，包括空白边，每个模块scale*scale个点，
+// 每行stride个字节，高位在前，1是黑色
+func (m *matrix) bitmap(quietZone, scale int) (bits []byte, stride int) {
+	n := (m.size + quietZone*2) * scale
+	stride = (n + 7) / 8
+	bits = make([]byte, stride*n)
+	for y := 0; y < m.size; y++ {
+		row := bits[(y+quietZone)*scale*stride:]
+		for x := 0; x < m.size; x++ {
+			if !m.Dark(x, y) {
+				continue
+			}
+			for i := (x + quietZone) * scale; i < (x+quietZone+1)*scale; i++ {
+				row[i/8] |= 0x80 >> (i % 8)
+			}
+		}
+		// 复制其他行
+		for i := 1; i < scale; i++ {
+			copy(row[i*stride:(i+1)*stride], row[:stride])
+		}
+	}
+	return
+}
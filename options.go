@@ -0,0 +1,59 @@
+package qrcode
+
+const (
+	defaultQuietZone  = 4
+	defaultModuleSize = 1
+)
+
+// 输出选项，各种输出格式共用
+type Options struct {
+	Level      Level   // 纠错等级
+	QuietZone  int     // 空白边的模块个数，0使用默认值4，小于0没有空白边
+	ModuleSize int     // 每个模块的像素，0使用默认值1
+	Colors     *Colors // 颜色，nil是白底黑点
+	// 版本号（1-40）大于等于这个值时，8种mark在多个goroutine中同时评分，0是不使用
+	ParallelMarkVersion int
+}
+
+// 空白边的模块个数
+func (o *Options) quietZone() int {
+	if o == nil || o.QuietZone == 0 {
+		return defaultQuietZone
+	}
+	if o.QuietZone < 0 {
+		return 0
+	}
+	return o.QuietZone
+}
+
+// 每个模块的像素
+func (o *Options) moduleSize() int {
+	if o == nil || o.ModuleSize <= 0 {
+		return defaultModuleSize
+	}
+	return o.ModuleSize
+}
+
+// 纠错等级
+func (o *Options) level() Level {
+	if o == nil {
+		return LevelL
+	}
+	return o.Level
+}
+
+// 颜色
+func (o *Options) colors() *Colors {
+	if o == nil {
+		return nil
+	}
+	return o.Colors
+}
+
+// 并发评分的版本号
+func (o *Options) parallelMarkVersion() int {
+	if o == nil {
+		return 0
+	}
+	return o.ParallelMarkVersion
+}
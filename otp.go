@@ -0,0 +1,194 @@
+package qrcode
+
+import (
+	"encoding/base32"
+	"fmt"
+	"image"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OTP的类型
+type OTPType int
+
+const (
+	TOTP OTPType = iota // 基于时间
+	HOTP                // 基于计数
+)
+
+// otpauth://URI
+type OTP struct {
+	Type      OTPType // 类型
+	Issuer    string  // 发行者
+	Account   string  // 账户
+	Secret    []byte  // 原始密钥，编码成base32
+	Algorithm string  // SHA1，SHA256或者SHA512，默认SHA1
+	Digits    int     // 6或者8，默认6
+	Period    int     // TOTP的周期，单位秒，默认30
+	Counter   uint64  // HOTP的初始计数
+}
+
+// base32编码的密钥，没有'='
+func (o *OTP) secret() string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(o.Secret)
+}
+
+// 生成URI
+func (o *OTP) Text() (string, error) {
+	if o.Account == "" {
+		return "", fmt.Errorf("otp account is empty")
+	}
+	if len(o.Secret) == 0 {
+		return "", fmt.Errorf("otp secret is empty")
+	}
+	if strings.Contains(o.Issuer, ":") {
+		return "", fmt.Errorf("otp issuer <%s> contains ':'", o.Issuer)
+	}
+	if strings.Contains(o.Account, ":") {
+		return "", fmt.Errorf("otp account <%s> contains ':'", o.Account)
+	}
+	var b strings.Builder
+	switch o.Type {
+	case TOTP:
+		b.WriteString("otpauth://totp/")
+	case HOTP:
+		b.WriteString("otpauth://hotp/")
+	default:
+		return "", fmt.Errorf("invalid otp type <%d>", o.Type)
+	}
+	// 标签，issuer:account
+	if o.Issuer != "" {
+		b.WriteString(percentEncode(o.Issuer))
+		b.WriteByte(':')
+	}
+	b.WriteString(percentEncode(o.Account))
+	b.WriteString("?secret=")
+	b.WriteString(o.secret())
+	if o.Issuer != "" {
+		b.WriteString("&issuer=")
+		b.WriteString(percentEncode(o.Issuer))
+	}
+	switch o.Algorithm {
+	case "", "SHA1":
+	case "SHA256", "SHA512":
+		b.WriteString("&algorithm=")
+		b.WriteString(o.Algorithm)
+	default:
+		return "", fmt.Errorf("invalid otp algorithm <%s>", o.Algorithm)
+	}
+	switch o.Digits {
+	case 0, 6:
+	case 8:
+		b.WriteString("&digits=8")
+	default:
+		return "", fmt.Errorf("invalid otp digits <%d>", o.Digits)
+	}
+	if o.Type == HOTP {
+		b.WriteString("&counter=")
+		b.WriteString(strconv.FormatUint(o.Counter, 10))
+	} else if o.Period != 0 && o.Period != 30 {
+		if o.Period < 0 {
+			return "", fmt.Errorf("invalid otp period <%d>", o.Period)
+		}
+		b.WriteString("&period=")
+		b.WriteString(strconv.Itoa(o.Period))
+	}
+	return b.String(), nil
+}
+
+// 生成图像，同时返回用于手动输入的密钥，每4个字符一组
+func (o *OTP) Image(level Level) (image.Image, string, error) {
+	s, err := o.Text()
+	if err != nil {
+		return nil, "", err
+	}
+	img, err := Image(s, level)
+	if err != nil {
+		return nil, "", err
+	}
+	secret := o.secret()
+	var b strings.Builder
+	for len(secret) > 4 {
+		b.WriteString(secret[:4])
+		b.WriteByte(' ')
+		secret = secret[4:]
+	}
+	b.WriteString(secret)
+	return img, b.String(), nil
+}
+
+// 百分号编码，除了字母，数字和"-._~"，空格编码成%20
+func percentEncode(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'a' && c <= 'z') || isUpperAlnum(c) || c == '-' || c == '.' || c == '_' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
+
+// 解析otpauth://URI
+func ParseOTP(str string) (*OTP, error) {
+	u, err := url.Parse(str)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "otpauth" {
+		return nil, fmt.Errorf("invalid otp scheme <%s>", u.Scheme)
+	}
+	o := new(OTP)
+	switch strings.ToLower(u.Host) {
+	case "totp":
+		o.Type = TOTP
+	case "hotp":
+		o.Type = HOTP
+	default:
+		return nil, fmt.Errorf("invalid otp type <%s>", u.Host)
+	}
+	// 标签，issuer:account
+	label := strings.TrimPrefix(u.Path, "/")
+	if i := strings.IndexByte(label, ':'); i >= 0 {
+		o.Issuer, o.Account = label[:i], strings.TrimLeft(label[i+1:], " ")
+	} else {
+		o.Account = label
+	}
+	q := u.Query()
+	if issuer := q.Get("issuer"); issuer != "" {
+		o.Issuer = issuer
+	}
+	secret := strings.ToUpper(strings.ReplaceAll(q.Get("secret"), " ", ""))
+	o.Secret, err = base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid otp secret <%s>", q.Get("secret"))
+	}
+	o.Algorithm = strings.ToUpper(q.Get("algorithm"))
+	if v := q.Get("digits"); v != "" {
+		if o.Digits, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid otp digits <%s>", v)
+		}
+	}
+	if v := q.Get("period"); v != "" {
+		if o.Period, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid otp period <%s>", v)
+		}
+	}
+	if v := q.Get("counter"); v != "" {
+		if o.Counter, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid otp counter <%s>", v)
+		}
+	} else if o.Type == HOTP {
+		return nil, fmt.Errorf("hotp counter is empty")
+	}
+	if _, err = o.Text(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
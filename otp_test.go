@@ -0,0 +1,68 @@
+package qrcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOTP(t *testing.T) {
+	for _, c := range []struct {
+		otp  OTP
+		want string
+	}{
+		{
+			OTP{Issuer: "ACME Co", Account: "john.doe@example.com", Secret: []byte("12345678901234567890")},
+			"otpauth://totp/ACME%20Co:john.doe%40example.com?secret=GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ&issuer=ACME%20Co",
+		},
+		{
+			OTP{Type: HOTP, Account: "a/b?c&d", Secret: []byte{1, 2, 3}, Algorithm: "SHA256", Digits: 8, Counter: 42},
+			"otpauth://hotp/a%2Fb%3Fc%26d?secret=AEBAG&algorithm=SHA256&digits=8&counter=42",
+		},
+		{
+			OTP{Issuer: "Été", Account: "x", Secret: []byte{0xff}, Period: 60},
+			"otpauth://totp/%C3%89t%C3%A9:x?secret=74&issuer=%C3%89t%C3%A9&period=60",
+		},
+	} {
+		s, err := c.otp.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != c.want {
+			t.Fatalf("got <%s>, want <%s>", s, c.want)
+		}
+	}
+	// issuer和account不能有':'
+	for _, o := range []OTP{
+		{Issuer: "a:b", Account: "x", Secret: []byte{1}},
+		{Account: "a:b", Secret: []byte{1}},
+		{Issuer: "i", Account: "a:b", Secret: []byte{1}},
+	} {
+		if _, err := o.Text(); err == nil {
+			t.Fatalf("<%s:%s> expect error", o.Issuer, o.Account)
+		}
+	}
+	// 解析结果一样
+	for _, o := range []OTP{
+		{Account: "a@b.c", Secret: []byte{1, 2, 3}},
+		{Issuer: "ACME Co", Account: "john doe", Secret: []byte{1, 2, 3}},
+	} {
+		s, err := o.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := ParseOTP(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Issuer != o.Issuer || p.Account != o.Account || !bytes.Equal(p.Secret, o.Secret) {
+			t.Fatalf("parse <%s> got %+v", s, p)
+		}
+	}
+	_, key, err := (&OTP{Account: "x", Secret: []byte("12345678901234567890")}).Image(LevelM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ" {
+		t.Fatalf("key got <%s>", key)
+	}
+}
@@ -0,0 +1,64 @@
+package qrcode
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// 网址，http或者https
+type URL string
+
+func (u URL) Text() (string, error) {
+	p, err := url.Parse(string(u))
+	if err != nil {
+		return "", err
+	}
+	if (p.Scheme != "http" && p.Scheme != "https") || p.Host == "" {
+		return "", fmt.Errorf("invalid url <%s>", string(u))
+	}
+	return string(u), nil
+}
+
+// 普通文本
+type PlainText string
+
+func (t PlainText) Text() (string, error) {
+	return string(t), nil
+}
+
+// 解析二维码的内容，返回*WiFi，*Contact，*EMVCo，*EPC，*OTP，*Event，*Geo，*SMS，Tel，*Mail，URL或者PlainText，
+// 能识别前缀但是内容不正确返回错误
+func ParsePayload(str string) (Payload, error) {
+	switch {
+	case hasPrefixFold(str, "WIFI:"):
+		return ParseWiFi(str)
+	case hasPrefixFold(str, "MECARD:"), hasPrefixFold(str, "BEGIN:VCARD"):
+		return ParseContact(str)
+	case hasPrefixFold(str, "BEGIN:VEVENT"), hasPrefixFold(str, "BEGIN:VCALENDAR"):
+		return parseEvent(str)
+	case hasPrefixFold(str, "otpauth://"):
+		return ParseOTP(str)
+	case hasPrefixFold(str, "geo:"):
+		return parseGeo(str)
+	case hasPrefixFold(str, "SMSTO:"), hasPrefixFold(str, "sms:"):
+		return parseSMS(str)
+	case hasPrefixFold(str, "tel:"):
+		return parseTel(str)
+	case hasPrefixFold(str, "mailto:"):
+		return parseMail(str)
+	case strings.HasPrefix(str, "BCD\n"), strings.HasPrefix(str, "BCD\r\n"):
+		return ParseEPC(str)
+	case strings.HasPrefix(str, emvPayloadFormat+"0201"):
+		// 纯数字开头的文本也可能是这个前缀，CRC不对当作文本
+		if e, err := ParseEMVCo(str); err == nil {
+			return e, nil
+		}
+	case hasPrefixFold(str, "http://"), hasPrefixFold(str, "https://"):
+		if _, err := URL(str).Text(); err != nil {
+			return nil, err
+		}
+		return URL(str), nil
+	}
+	return PlainText(str), nil
+}
@@ -0,0 +1,54 @@
+package qrcode
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParsePayload(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	for _, p := range []Payload{
+		&WiFi{SSID: "home", Auth: WiFiWPA, Password: "secret"},
+		&Contact{Format: VCard4, FirstName: "San", LastName: "Zhang", Phones: []Phone{{Type: "cell", Number: "+8613800000000"}}, Emails: []string{"z@example.com"}},
+		&Contact{Format: MeCard, FirstName: "San", LastName: "Zhang", Emails: []string{"z@example.com"}},
+		&EPC{BIC: "BHBLDEHHXXX", Name: "Franz Mustermann", IBAN: "DE71110220330123456789", Amount: "12.30", Remittance: "Invoice 1"},
+		&OTP{Issuer: "ACME", Account: "alice@example.com", Secret: []byte("12345678901234567890"), Digits: 8},
+		&Event{Summary: "Meeting; room 1", Start: start, End: start.Add(time.Hour), Location: "Office"},
+		&Geo{Latitude: 31.2304, Longitude: 121.4737, Query: "上海"},
+		&SMS{Number: "+8613800000000", Message: "hi: there"},
+		Tel("+8613800000000"),
+		&Mail{To: []string{"a@example.com"}, Cc: []string{"b@example.com"}, Subject: "Hello world", Body: "a&b"},
+		URL("https://example.com/a?b=c"),
+		PlainText("hello"),
+	} {
+		s, err := p.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		q, err := ParsePayload(s)
+		if err != nil {
+			t.Fatalf("parse <%s>: %v", s, err)
+		}
+		if c, ok := q.(*Contact); ok {
+			c.Format = p.(*Contact).Format
+		}
+		if !reflect.DeepEqual(p, q) {
+			t.Fatalf("parse <%s> got %#v, want %#v", s, q, p)
+		}
+	}
+	// EMVCo，CRC不对当作文本
+	e := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-426655440000" +
+		"5204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***6304"
+	if p, _ := ParsePayload(e + "1D3D"); reflect.TypeOf(p) != reflect.TypeOf(&EMVCo{}) {
+		t.Fatalf("expect emvco, got %T", p)
+	}
+	if p, _ := ParsePayload(e + "0000"); reflect.TypeOf(p) != reflect.TypeOf(PlainText("")) {
+		t.Fatalf("expect plain text, got %T", p)
+	}
+	for _, s := range []string{"WIFI:S:x", "geo:abc,1", "tel:abc", "http://"} {
+		if _, err := ParsePayload(s); err == nil {
+			t.Fatalf("expect error for <%s>", s)
+		}
+	}
+}
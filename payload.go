@@ -0,0 +1,370 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 二维码的内容，WiFi，Contact，EMVCo，EPC，SwissQRBill，OTP，Event，Geo，SMS，Tel，Mail，Signed和Encrypted都实现了这个接口
+type Payload interface {
+	Text() (string, error)
+}
+
+func PayloadPNG(w io.Writer, p Payload, level Level, compress png.CompressionLevel) error {
+	str, err := p.Text()
+	if err != nil {
+		return err
+	}
+	return PNG(w, str, level, compress)
+}
+
+func PayloadJPEG(w io.Writer, p Payload, level Level, quality int) error {
+	str, err := p.Text()
+	if err != nil {
+		return err
+	}
+	return JPEG(w, str, level, quality)
+}
+
+func PayloadImage(p Payload, level Level) (image.Image, error) {
+	str, err := p.Text()
+	if err != nil {
+		return nil, err
+	}
+	return Image(str, level)
+}
+
+// 日历事件，BEGIN:VEVENT
+type Event struct {
+	Summary     string    // 标题
+	Start       time.Time // 开始时间
+	End         time.Time // 结束时间，可以为零值
+	AllDay      bool      // 全天事件，只使用日期
+	Location    string    // 地点
+	Description string    // 描述
+}
+
+func (e *Event) Text() (string, error) {
+	if e.Summary == "" {
+		return "", fmt.Errorf("event summary is empty")
+	}
+	if e.Start.IsZero() {
+		return "", fmt.Errorf("event start time is zero")
+	}
+	if !e.End.IsZero() && e.End.Before(e.Start) {
+		return "", fmt.Errorf("event end time is before start time")
+	}
+	date := func(name string, t time.Time) string {
+		if e.AllDay {
+			return name + ";VALUE=DATE:" + t.Format("20060102")
+		}
+		return name + ":" + t.UTC().Format("20060102T150405Z")
+	}
+	var b strings.Builder
+	vcardFold(&b, "BEGIN:VEVENT")
+	vcardFold(&b, "SUMMARY:"+vcardEscape(e.Summary))
+	vcardFold(&b, date("DTSTART", e.Start))
+	if !e.End.IsZero() {
+		vcardFold(&b, date("DTEND", e.End))
+	}
+	if e.Location != "" {
+		vcardFold(&b, "LOCATION:"+vcardEscape(e.Location))
+	}
+	if e.Description != "" {
+		vcardFold(&b, "DESCRIPTION:"+vcardEscape(e.Description))
+	}
+	vcardFold(&b, "END:VEVENT")
+	return b.String(), nil
+}
+
+// 地理位置，geo:latitude,longitude?q=query
+type Geo struct {
+	Latitude  float64 // 纬度，范围[-90,90]
+	Longitude float64 // 经度，范围[-180,180]
+	Query     string  // 地点名称，可以为空
+}
+
+func (g *Geo) Text() (string, error) {
+	if math.IsNaN(g.Latitude) || g.Latitude < -90 || g.Latitude > 90 {
+		return "", fmt.Errorf("invalid geo latitude <%v>", g.Latitude)
+	}
+	if math.IsNaN(g.Longitude) || g.Longitude < -180 || g.Longitude > 180 {
+		return "", fmt.Errorf("invalid geo longitude <%v>", g.Longitude)
+	}
+	s := "geo:" + strconv.FormatFloat(g.Latitude, 'f', -1, 64) + "," + strconv.FormatFloat(g.Longitude, 'f', -1, 64)
+	if g.Query != "" {
+		s += "?q=" + percentEncode(g.Query)
+	}
+	return s, nil
+}
+
+// 短信，SMSTO:number:message
+type SMS struct {
+	Number  string // 号码
+	Message string // 内容，可以为空
+}
+
+func (s *SMS) Text() (string, error) {
+	n, err := telNumber(s.Number)
+	if err != nil {
+		return "", err
+	}
+	return "SMSTO:" + n + ":" + s.Message, nil
+}
+
+// 电话，tel:number
+type Tel string
+
+func (t Tel) Text() (string, error) {
+	n, err := telNumber(string(t))
+	if err != nil {
+		return "", err
+	}
+	return "tel:" + n, nil
+}
+
+// 电子邮件，mailto:to?subject=...&body=...
+type Mail struct {
+	To      []string // 收件人
+	Cc      []string // 抄送
+	Bcc     []string // 密送
+	Subject string   // 主题
+	Body    string   // 内容
+}
+
+func (m *Mail) Text() (string, error) {
+	if len(m.To) == 0 {
+		return "", fmt.Errorf("mail recipient is empty")
+	}
+	addrs := func(a []string) (string, error) {
+		for i := range a {
+			if strings.Count(a[i], "@") != 1 {
+				return "", fmt.Errorf("invalid mail address <%s>", a[i])
+			}
+		}
+		return mailtoEncode(strings.Join(a, ",")), nil
+	}
+	to, err := addrs(m.To)
+	if err != nil {
+		return "", err
+	}
+	var q []string
+	for _, f := range []struct {
+		name string
+		addr []string
+	}{
+		{"cc", m.Cc},
+		{"bcc", m.Bcc},
+	} {
+		if len(f.addr) > 0 {
+			a, err := addrs(f.addr)
+			if err != nil {
+				return "", err
+			}
+			q = append(q, f.name+"="+a)
+		}
+	}
+	if m.Subject != "" {
+		q = append(q, "subject="+percentEncode(m.Subject))
+	}
+	if m.Body != "" {
+		q = append(q, "body="+percentEncode(m.Body))
+	}
+	s := "mailto:" + to
+	if len(q) > 0 {
+		s += "?" + strings.Join(q, "&")
+	}
+	return s, nil
+}
+
+// 检查电话号码，去掉空格，只能有数字，"+"，"-"，"."，"("和")"
+func telNumber(s string) (string, error) {
+	n := strings.ReplaceAll(s, " ", "")
+	if n == "" {
+		return "", fmt.Errorf("phone number is empty")
+	}
+	for i := 0; i < len(n); i++ {
+		if !strings.ContainsRune("0123456789+-.()", rune(n[i])) {
+			return "", fmt.Errorf("invalid phone number <%s>", s)
+		}
+	}
+	return n, nil
+}
+
+// 邮件地址的百分号编码，保留'@'和','
+func mailtoEncode(s string) string {
+	return strings.NewReplacer("%40", "@", "%2C", ",").Replace(percentEncode(s))
+}
+
+// 解析BEGIN:VEVENT，可以在BEGIN:VCALENDAR里面
+func parseEvent(str string) (*Event, error) {
+	e := new(Event)
+	in, end := false, false
+	date := func(params []string, value string) (time.Time, error) {
+		if len(value) == 8 {
+			e.AllDay = true
+			return time.ParseInLocation("20060102", value, time.Local)
+		}
+		if strings.HasSuffix(value, "Z") {
+			return time.Parse("20060102T150405Z", value)
+		}
+		// 没有时区的本地时间，忽略TZID
+		return time.ParseInLocation("20060102T150405", value, time.Local)
+	}
+	for _, line := range unfoldLines(str) {
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		params := strings.Split(line[:i], ";")
+		name, value := strings.ToUpper(params[0]), line[i+1:]
+		if name == "BEGIN" && strings.EqualFold(value, "VEVENT") {
+			in = true
+			continue
+		}
+		if !in {
+			continue
+		}
+		var err error
+		switch name {
+		case "SUMMARY":
+			e.Summary = vcardUnescape(value)
+		case "DTSTART":
+			e.Start, err = date(params, value)
+		case "DTEND":
+			e.End, err = date(params, value)
+		case "LOCATION":
+			e.Location = vcardUnescape(value)
+		case "DESCRIPTION":
+			e.Description = vcardUnescape(value)
+		case "END":
+			end = strings.EqualFold(value, "VEVENT")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid event %s <%s>", strings.ToLower(name), value)
+		}
+		if end {
+			break
+		}
+	}
+	if !end {
+		return nil, fmt.Errorf("event END not found")
+	}
+	if _, err := e.Text(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// 解析geo:latitude,longitude[,altitude][;params][?q=query]
+func parseGeo(str string) (*Geo, error) {
+	s := str[len("geo:"):]
+	g := new(Geo)
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		q, err := url.ParseQuery(s[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid geo query <%s>", s[i+1:])
+		}
+		g.Query = q.Get("q")
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, ';'); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("invalid geo <%s>", str)
+	}
+	var err error
+	if g.Latitude, err = strconv.ParseFloat(parts[0], 64); err != nil {
+		return nil, fmt.Errorf("invalid geo latitude <%s>", parts[0])
+	}
+	if g.Longitude, err = strconv.ParseFloat(parts[1], 64); err != nil {
+		return nil, fmt.Errorf("invalid geo longitude <%s>", parts[1])
+	}
+	if _, err = g.Text(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// 解析SMSTO:number:message或者sms:number?body=message
+func parseSMS(str string) (*SMS, error) {
+	s := new(SMS)
+	if hasPrefixFold(str, "SMSTO:") {
+		str = str[len("SMSTO:"):]
+		if i := strings.IndexByte(str, ':'); i >= 0 {
+			s.Number, s.Message = str[:i], str[i+1:]
+		} else {
+			s.Number = str
+		}
+	} else {
+		str = str[len("sms:"):]
+		if i := strings.IndexByte(str, '?'); i >= 0 {
+			q, err := url.ParseQuery(str[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid sms query <%s>", str[i+1:])
+			}
+			s.Message = q.Get("body")
+			str = str[:i]
+		}
+		s.Number = str
+	}
+	n, err := telNumber(s.Number)
+	if err != nil {
+		return nil, err
+	}
+	s.Number = n
+	return s, nil
+}
+
+// 解析tel:number
+func parseTel(str string) (Tel, error) {
+	n, err := url.PathUnescape(str[len("tel:"):])
+	if err != nil {
+		return "", fmt.Errorf("invalid tel <%s>", str)
+	}
+	if n, err = telNumber(n); err != nil {
+		return "", err
+	}
+	return Tel(n), nil
+}
+
+// 解析mailto:to?cc=...&bcc=...&subject=...&body=...
+func parseMail(str string) (*Mail, error) {
+	u, err := url.Parse(str)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Mail)
+	addrs := func(s string) []string {
+		var a []string
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				a = append(a, v)
+			}
+		}
+		return a
+	}
+	to, err := url.PathUnescape(u.Opaque)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail recipient <%s>", u.Opaque)
+	}
+	q := u.Query()
+	m.To = append(addrs(to), addrs(q.Get("to"))...)
+	m.Cc = addrs(q.Get("cc"))
+	m.Bcc = addrs(q.Get("bcc"))
+	m.Subject = q.Get("subject")
+	m.Body = q.Get("body")
+	if _, err = m.Text(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
@@ -0,0 +1,66 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEvent(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	e := &Event{
+		Summary:     "Meeting; Q3, plan",
+		Start:       time.Date(2024, 3, 1, 9, 30, 0, 0, loc),
+		End:         time.Date(2024, 3, 1, 10, 0, 0, 0, loc),
+		Location:    `Room \1`,
+		Description: strings.Repeat("0123456789", 10),
+	}
+	s, err := e.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "BEGIN:VEVENT\r\nSUMMARY:Meeting\\; Q3\\, plan\r\n" +
+		"DTSTART:20240301T013000Z\r\nDTEND:20240301T020000Z\r\nLOCATION:Room \\\\1\r\n" +
+		// 75个字节折行，后面的行以空格开头
+		"DESCRIPTION:" + strings.Repeat("0123456789", 6) + "012\r\n " +
+		"3456789" + strings.Repeat("0123456789", 3) + "\r\nEND:VEVENT\r\n"
+	if s != want {
+		t.Fatalf("got <%q>", s)
+	}
+	e = &Event{Summary: "x", Start: time.Date(2024, 3, 1, 0, 0, 0, 0, loc), AllDay: true}
+	if s, _ = e.Text(); s != "BEGIN:VEVENT\r\nSUMMARY:x\r\nDTSTART;VALUE=DATE:20240301\r\nEND:VEVENT\r\n" {
+		t.Fatalf("got <%q>", s)
+	}
+	e.End = e.Start.Add(-time.Hour)
+	if _, err = e.Text(); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestPayloadText(t *testing.T) {
+	for _, c := range []struct {
+		p    Payload
+		want string
+	}{
+		{&Geo{Latitude: 48.8584, Longitude: 2.2945, Query: "Tour Eiffel"}, "geo:48.8584,2.2945?q=Tour%20Eiffel"},
+		{&SMS{Number: "+1 555 0100", Message: "hi: there"}, "SMSTO:+15550100:hi: there"},
+		{Tel("+1 (555) 0100"), "tel:+1(555)0100"},
+		{
+			&Mail{To: []string{"a@x.com", "b@x.com"}, Cc: []string{"c+d@x.com"}, Subject: "Hi & bye", Body: "1\n2"},
+			"mailto:a@x.com,b@x.com?cc=c%2Bd@x.com&subject=Hi%20%26%20bye&body=1%0A2",
+		},
+	} {
+		s, err := c.p.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s != c.want {
+			t.Fatalf("got <%s>, want <%s>", s, c.want)
+		}
+	}
+	for _, p := range []Payload{&Geo{Latitude: 91}, Tel("555-abc"), &Mail{To: []string{"x"}}} {
+		if _, err := p.Text(); err == nil {
+			t.Fatalf("%v expect error", p)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package qrcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	Point      = 1.0         // 1点，PDF的单位
+	Millimeter = 72.0 / 25.4 // 1毫米的点数
+	Inch       = 72.0        // 1英寸的点数
+
+	defaultPDFSize     = 30 * Millimeter
+	defaultPDFFont     = "Helvetica"
+	defaultPDFFontSize = 10
+)
+
+var (
+	// PDF的14种标准字体
+	pdfBase14Fonts = map[string]bool{
+		"Times-Roman": true, "Times-Bold": true, "Times-Italic": true, "Times-BoldItalic": true,
+		"Helvetica": true, "Helvetica-Bold": true, "Helvetica-Oblique": true, "Helvetica-BoldOblique": true,
+		"Courier": true, "Courier-Bold": true, "Courier-Oblique": true, "Courier-BoldOblique": true,
+		"Symbol": true, "ZapfDingbats": true,
+	}
+	// WinAnsiEncoding的0x80-0x9F和Latin-1不同
+	pdfWinAnsiTable = map[rune]byte{
+		'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+		'‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93,
+		'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b,
+		'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+	}
+	// Helvetica字符' '到'~'的宽度，单位1/1000字号
+	helveticaWidthTable = [95]int{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}
+)
+
+// PDF选项
+type PDFOptions struct {
+	Options          // 共用选项，ModuleSize是每个模块的点数，Size是0时使用
+	Size     float64 // 二维码的边长，包括空白边，单位点，0使用ModuleSize，都是0使用默认值30毫米
+	Caption  string  // 二维码下方的文字，只支持WinAnsiEncoding的字符，比二维码宽时加宽页面
+	Font     string  // 文字的字体，必须是14种标准字体之一，默认Helvetica
+	FontSize float64 // 文字的字号，单位点，默认10
+}
+
+// 输出一页PDF，二维码的模块是矢量矩形
+func PDF(w io.Writer, str string, opt *PDFOptions) error {
+	if opt == nil {
+		opt = new(PDFOptions)
+	}
+	font := opt.Font
+	if font == "" {
+		font = defaultPDFFont
+	}
+	if !pdfBase14Fonts[font] {
+		return fmt.Errorf("font <%s> is not a PDF base-14 font", font)
+	}
+	fontSize := opt.FontSize
+	if fontSize <= 0 {
+		fontSize = defaultPDFFontSize
+	}
+	var text []byte
+	if opt.Caption != "" {
+		b, err := pdfWinAnsi(opt.Caption)
+		if err != nil {
+			return err
+		}
+		text = b
+	}
+	m, err := newMatrix(str, &opt.Options)
+	if err != nil {
+		return err
+	}
+	c := opt.colors()
+	qz := opt.quietZone()
+	// 边长
+	size := opt.Size
+	if size <= 0 {
+		size = defaultPDFSize
+		if opt.ModuleSize > 0 {
+			size = float64((m.size + qz*2) * opt.ModuleSize)
+		}
+	}
+	// 页面，文字在二维码下方，两边至少留半个字号
+	width, height := size, size
+	textWidth := pdfTextWidth(font, text) * fontSize / 1000
+	if text != nil {
+		height += fontSize * 2
+		if w := textWidth + fontSize; w > width {
+			width = w
+		}
+	}
+	unit := size / float64(m.size+qz*2)
+	// 内容
+	var content bytes.Buffer
+	if c != nil && c.Background != nil {
+		fmt.Fprintf(&content, "%s rg 0 0 %s %s re f\n", pdfColor(c.Background), pdfNum(width), pdfNum(height))
+	}
+	// 坐标变换，原点在二维码左上角，单位是模块，二维码水平居中
+	fmt.Fprintf(&content, "q %s 0 0 %s %s %s cm\n", pdfNum(unit), pdfNum(-unit), pdfNum((width-size)/2), pdfNum(height))
+	var last color.Color
+	err = m.colorRuns(c, func(x, y, n int, fg color.Color) {
+		if last == nil || !sameColor(fg, last) {
+			if last != nil {
+				content.WriteString("f\n")
+			}
+			fmt.Fprintf(&content, "%s rg\n", pdfColor(fg))
+			last = fg
+		}
+		fmt.Fprintf(&content, "%d %d %d 1 re\n", x+qz, y+qz, n)
+	})
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		content.WriteString("f\n")
+	}
+	content.WriteString("Q\n")
+	if text != nil {
+		tx := (width - textWidth) / 2
+		fmt.Fprintf(&content, "0 g BT /F1 %s Tf %s %s Td (%s) Tj ET\n",
+			pdfNum(fontSize), pdfNum(tx), pdfNum(fontSize*0.7), pdfEscape(text))
+	}
+	// 压缩
+	var stream bytes.Buffer
+	zw := zlib.NewWriter(&stream)
+	zw.Write(content.Bytes())
+	zw.Close()
+	// 符号字体使用内置编码
+	encoding := " /Encoding /WinAnsiEncoding"
+	if font == "Symbol" || font == "ZapfDingbats" {
+		encoding = ""
+	}
+	// 对象
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+			pdfNum(width), pdfNum(height)),
+		fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()),
+		fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s%s >>", font, encoding),
+	}
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objs))
+	for i, o := range objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, n := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", n)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// 格式化数字，最多保留3位小数
+func pdfNum(f float64) string {
+	s := strconv.FormatFloat(f, 'f', 3, 64)
+	for s[len(s)-1] == '0' {
+		s = s[:len(s)-1]
+	}
+	if s[len(s)-1] == '.' {
+		s = s[:len(s)-1]
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// 颜色的rgb分量，范围[0,1]，不预乘alpha
+func pdfColor(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return pdfNum(float64(n.R)/0xff) + " " + pdfNum(float64(n.G)/0xff) + " " + pdfNum(float64(n.B)/0xff)
+}
+
+// 转换成WinAnsiEncoding，不支持的字符返回错误
+func pdfWinAnsi(s string) ([]byte, error) {
+	b := make([]byte, 0, len(s))
+	for _, c := range s {
+		switch {
+		case c >= ' ' && c < 0x7f, c >= 0xa0 && c <= 0xff:
+			b = append(b, byte(c))
+		default:
+			n, ok := pdfWinAnsiTable[c]
+			if !ok {
+				return nil, fmt.Errorf("caption character <%q> not in WinAnsiEncoding", c)
+			}
+			b = append(b, n)
+		}
+	}
+	return b, nil
+}
+
+// 字符串转义
+func pdfEscape(b []byte) string {
+	var s bytes.Buffer
+	for _, c := range b {
+		switch c {
+		case '(', ')', '\\':
+			s.WriteByte('\\')
+			s.WriteByte(c)
+		default:
+			if c < ' ' || c > '~' {
+				fmt.Fprintf(&s, "\\%03o", c)
+			} else {
+				s.WriteByte(c)
+			}
+		}
+	}
+	return s.String()
+}
+
+// 文字的宽度，单位1/1000字号，Courier是等宽字体，其他字体使用Helvetica的宽度近似
+func pdfTextWidth(font string, b []byte) float64 {
+	w := 0
+	for _, c := range b {
+		if strings.HasPrefix(font, "Courier") {
+			w += 600
+		} else if c >= ' ' && c <= '~' {
+			w += helveticaWidthTable[c-' ']
+		} else {
+			w += 556
+		}
+	}
+	return float64(w)
+}
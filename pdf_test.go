@@ -0,0 +1,139 @@
+package qrcode
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 检查PDF的结构，返回解压后的内容
+func testPDFContent(t *testing.T, out []byte) string {
+	if !bytes.HasPrefix(out, []byte("%PDF-1.4\n")) {
+		t.Fatal("missing header")
+	}
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	table := string(out[xref:])
+	if !strings.HasPrefix(table, "xref\n0 6\n0000000000 65535 f \n") {
+		t.Fatalf("invalid xref <%.30s>", table)
+	}
+	lines := strings.Split(table, "\n")
+	for i := 1; i <= 5; i++ {
+		if len(lines[i+2]) != 19 || !strings.HasSuffix(lines[i+2], " 00000 n ") {
+			t.Fatalf("invalid xref entry <%s>", lines[i+2])
+		}
+		off, _ := strconv.Atoi(lines[i+2][:10])
+		if !bytes.HasPrefix(out[off:], []byte(fmt.Sprintf("%d 0 obj\n", i))) {
+			t.Fatalf("object %d offset %d is wrong", i, off)
+		}
+	}
+	if !strings.Contains(table, "trailer\n<< /Size 6 /Root 1 0 R >>") {
+		t.Fatal("invalid trailer")
+	}
+	// 内容的长度
+	m = regexp.MustCompile(`<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`).FindSubmatch(out)
+	if m == nil {
+		t.Fatal("missing stream")
+	}
+	n, _ := strconv.Atoi(string(m[1]))
+	i := bytes.Index(out, m[0]) + len(m[0])
+	if !bytes.HasPrefix(out[i+n:], []byte("\nendstream")) {
+		t.Fatal("invalid stream length")
+	}
+	r, err := zlib.NewReader(bytes.NewReader(out[i : i+n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestPDF(t *testing.T) {
+	str := "Hello World!"
+	m, err := newMatrix(str, &Options{Level: LevelM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dark := 0
+	for _, c := range m.pix {
+		if c == _paletteBlack {
+			dark++
+		}
+	}
+	var out bytes.Buffer
+	err = PDF(&out, str, &PDFOptions{
+		Options: Options{
+			Level:  LevelM,
+			Colors: &Colors{Data: Solid{color.NRGBA{R: 255, A: 128}}, MinContrast: 1},
+		},
+		Caption: "10€ – ok",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := testPDFContent(t, out.Bytes())
+	// 每个矩形的模块个数之和
+	n := 0
+	for _, s := range regexp.MustCompile(`\d+ \d+ (\d+) 1 re`).FindAllStringSubmatch(content, -1) {
+		i, _ := strconv.Atoi(s[1])
+		n += i
+	}
+	if n != dark {
+		t.Fatalf("dark modules got %d, want %d", n, dark)
+	}
+	// 颜色不预乘alpha
+	if !strings.Contains(content, "\n1 0 0 rg\n") {
+		t.Fatalf("missing color in <%s>", content[:100])
+	}
+	// WinAnsiEncoding
+	if !strings.Contains(content, `(10\200 \226 ok) Tj`) {
+		t.Fatal("caption is not WinAnsiEncoding")
+	}
+	// ModuleSize
+	out.Reset()
+	if err = PDF(&out, str, &PDFOptions{Options: Options{Level: LevelM, ModuleSize: 2}}); err != nil {
+		t.Fatal(err)
+	}
+	testPDFContent(t, out.Bytes())
+	box := fmt.Sprintf("/MediaBox [0 0 %d %d]", (m.size+8)*2, (m.size+8)*2)
+	if !bytes.Contains(out.Bytes(), []byte(box)) {
+		t.Fatalf("missing <%s>", box)
+	}
+	// 文字比二维码宽，加宽页面，二维码居中
+	out.Reset()
+	caption := strings.Repeat("W", 40)
+	if err = PDF(&out, str, &PDFOptions{Options: Options{Level: LevelM}, Caption: caption}); err != nil {
+		t.Fatal(err)
+	}
+	content = testPDFContent(t, out.Bytes())
+	width := pdfTextWidth(defaultPDFFont, []byte(caption))*defaultPDFFontSize/1000 + defaultPDFFontSize
+	box = fmt.Sprintf("/MediaBox [0 0 %s %s]", pdfNum(width), pdfNum(defaultPDFSize+defaultPDFFontSize*2))
+	if !bytes.Contains(out.Bytes(), []byte(box)) {
+		t.Fatalf("missing <%s>", box)
+	}
+	cm := fmt.Sprintf(" %s %s cm\n", pdfNum((width-defaultPDFSize)/2), pdfNum(defaultPDFSize+defaultPDFFontSize*2))
+	if !strings.Contains(content, cm) {
+		t.Fatalf("missing <%s>", cm)
+	}
+	if !strings.Contains(content, fmt.Sprintf(" %s %s Td ", pdfNum(defaultPDFFontSize/2.0), pdfNum(defaultPDFFontSize*0.7))) {
+		t.Fatal("caption is not centered")
+	}
+	// 不支持的字符
+	for _, caption := range []string{"你好", "a\nb"} {
+		if PDF(&out, str, &PDFOptions{Caption: caption}) == nil {
+			t.Fatalf("caption <%q> expect error", caption)
+		}
+	}
+}
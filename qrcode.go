@@ -5,12 +5,12 @@ package qrcode
 */
 
 import (
+	"fmt"
 	"image"
 	"image/color"
 	"image/jpeg"
 	"image/png"
 	"io"
-	"math"
 	"sync"
 )
 
@@ -1017,26 +1017,12 @@ var (
 			return ((x+y)%2+(x*y)%3)%2 == 0
 		},
 	}
-	evaluation3Bytes = [2][]uint8{
-		{
-			_paletteBlack, _paletteWhite, _paletteBlack, _paletteBlack, _paletteBlack,
-			_paletteWhite, _paletteBlack, _paletteWhite, _paletteWhite, _paletteWhite, _paletteWhite,
-		},
-		{
-			_paletteWhite, _paletteWhite, _paletteWhite, _paletteWhite, _paletteBlack,
-			_paletteWhite, _paletteBlack, _paletteBlack, _paletteBlack, _paletteWhite, _paletteBlack,
-		},
-	} // 0水平，1垂直
 )
 
 func init() {
 	_pool.New = func() interface{} {
 		q := new(qrCode)
-		q.buffer.data = make([]byte, 1)
-		q.strEnc.bitD = make([]byte, 1)
-		q.strEnc.buff = &q.buffer
-		q.eccEnc.poly = make([]byte, 1)
-		q.eccEnc.buff = &q.buffer
+		q.init()
 		return q
 	}
 }
@@ -1063,22 +1049,14 @@ func JPEG(w io.Writer, str string, level Level, quality int) error {
 
 func Image(str string, level Level) (image.Image, error) {
 	q := _pool.Get().(*qrCode)
-	// 字符串编码
-	err := q.strEnc.Encode(str, level)
+	err := q.Encode(str, level)
 	if err != nil {
 		_pool.Put(q)
 		return nil, err
 	}
-	// 纠错编码
-	q.eccEnc.Encode(q.strEnc.bitD, q.strEnc.version, level)
 	// 位图
 	img := new(image.Paletted)
-	img.Stride = qrCodeSizeTable[q.strEnc.version] + 8
-	img.Rect.Max.X = img.Stride
-	img.Rect.Max.Y = img.Stride
-	img.Palette = _palette
-	img.Pix = make([]uint8, img.Stride*img.Stride)
-	q.Draw(img)
+	q.DrawImage(img, nil)
 	// 回收缓存
 	_pool.Put(q)
 	// 返回
@@ -1108,146 +1086,161 @@ func (b *buffer) Resize(n, o int) {
 }
 
 type qrCode struct {
-	buffer     buffer     // 共享缓存
-	strEnc     strEncoder // 字符串编码
-	eccEnc     eccEncoder // 纠错编码
-	pixXY      [][]uint8  // 位图二维数组指针
-	markNum    int        // 使用的mark图编号
-	markData   buffer     // mark后的最终数据
-	markBuffXY [][]uint8  // mark后的缓存数组的二维指针
-	markDataXY [][]uint8  // mark后的缓存数组的二维指针
+	buffer   buffer     // 共享缓存
+	strEnc   strEncoder // 字符串编码
+	eccEnc   eccEncoder // 纠错编码
+	pix      markMatrix // 位图，mark之前是原始数据
+	markBuff markMatrix // mark的缓存
+	markNum  int        // 使用的mark图编号
+	// 版本号大于等于这个值时并发评分，0是不使用，见Options.ParallelMarkVersion
+	parallelMarkVersion int
+	// 并发评分时，每个goroutine的缓存
+	markBuffs [maxMark]markMatrix
 }
 
-// 画图
-func (q *qrCode) Draw(img *image.Paletted) {
-	// 图像数据，包括两边的4个空白
-	q.buffer.Resize((img.Stride-8)*(img.Stride-8), -1)
-	q.markData.Resize(len(q.buffer.data), -1)
-	// 二维表，便于操作
-	pix1 := img.Pix[4*(img.Stride)+4:]
-	pix2 := q.buffer.data
-	pix3 := q.markData.data
-	q.pixXY = q.pixXY[:0]
-	q.markBuffXY = q.markBuffXY[:0]
-	q.markDataXY = q.markDataXY[:0]
-	for i := 0; i < qrCodeSizeTable[q.strEnc.version]; i++ {
-		q.pixXY = append(q.pixXY, pix1[:qrCodeSizeTable[q.strEnc.version]])
-		pix1 = pix1[qrCodeSizeTable[q.strEnc.version]+8:]
-		q.markBuffXY = append(q.markBuffXY, pix2[:qrCodeSizeTable[q.strEnc.version]])
-		pix2 = pix2[qrCodeSizeTable[q.strEnc.version]:]
-		q.markDataXY = append(q.markDataXY, pix3[:qrCodeSizeTable[q.strEnc.version]])
-		pix3 = pix3[qrCodeSizeTable[q.strEnc.version]:]
-	}
-	// 开始画图
-	q.drawFinderPatterns()
-	q.drawTimingPatterns()
-	q.drawAlignmentPatterns()
-	q.drawBottomLeftPoint()
-	q.drawData()
-	q.mark()
-	q.drawFormatInformation()
-	q.drawVersionInformation()
+// 初始化缓存
+func (q *qrCode) init() {
+	q.buffer.data = make([]byte, 1)
+	q.strEnc.bitD = make([]byte, 1)
+	q.strEnc.buff = &q.buffer
+	q.eccEnc.buff = &q.buffer
 }
 
-// 画点
-func (q *qrCode) drawPoint(x, y int, c uint8) {
-	q.pixXY[y][x] = c
+// 字符串编码和纠错编码，不使用并发评分
+func (q *qrCode) Encode(str string, level Level) error {
+	q.parallelMarkVersion = 0
+	// 字符串编码
+	err := q.strEnc.Encode(str, level)
+	if err != nil {
+		return err
+	}
+	// 纠错编码
+	q.eccEnc.Encode(q.strEnc.bitD, q.strEnc.version, level)
+	return nil
 }
 
-// 画矩形
-func (q *qrCode) drawRectangle(x1, y1, x2, y2 int, c uint8) {
-	// 上下
-	for x := x1; x <= x2; x++ {
-		q.drawPoint(x, y1, c)
-		q.drawPoint(x, y2, c)
-	}
-	// 左右
-	for y := y1 + 1; y < y2; y++ {
-		q.drawPoint(x1, y, c)
-		q.drawPoint(x2, y, c)
-	}
+// 使用opt的纠错等级和并发评分编码
+func (q *qrCode) EncodeOptions(str string, opt *Options) error {
+	err := q.Encode(str, opt.level())
+	q.parallelMarkVersion = opt.parallelMarkVersion()
+	return err
 }
 
-// 画矩形
-func (q *qrCode) drawSolidRectangle(x1, y1, x2, y2 int, c uint8) {
-	for y := y1; y <= y2; y++ {
-		for x := x1; x <= x2; x++ {
-			q.drawPoint(x, y, c)
+// 按照opt设置img的大小和调色板，然后画图，颜色只能是Solid。
+// img.Pix和img.Palette容量足够时不分配内存
+func (q *qrCode) DrawImage(img *image.Paletted, opt *Options) error {
+	// 每个区域的颜色在调色板中的下标，0是背景
+	var index [maxRegion]uint8
+	c := opt.colors()
+	if c == nil {
+		img.Palette = _palette
+		for i := range index {
+			index[i] = _paletteBlack
+		}
+	} else {
+		p := img.Palette
+		// 不能修改共用的_palette
+		if len(p) > 0 && &p[0] == &_palette[0] {
+			p = nil
 		}
+		p = append(p[:0], c.background())
+		for r := Region(0); r < maxRegion; r++ {
+			s, ok := c.paint(r).(Solid)
+			if !ok {
+				return fmt.Errorf("paint of %s modules is not solid", r)
+			}
+			err := c.checkContrast(r, s.Color)
+			if err != nil {
+				return err
+			}
+			index[r] = uint8(len(p))
+			for i := range p {
+				if sameColor(p[i], s.Color) {
+					index[r] = uint8(i)
+					break
+				}
+			}
+			if int(index[r]) == len(p) {
+				p = append(p, s.Color)
+			}
+		}
+		img.Palette = p
 	}
-}
-
-// finder patterns
-func (q *qrCode) drawFinderPatterns() {
-	// 左上角
-	q.drawRectangle(0, 0, 6, 6, _paletteBlack)
-	q.drawSolidRectangle(2, 2, 4, 4, _paletteBlack)
-	// 右上角
-	q.drawRectangle(qrCodeSizeTable[q.strEnc.version]-7, 0,
-		qrCodeSizeTable[q.strEnc.version]-1, 6, _paletteBlack)
-	q.drawSolidRectangle(qrCodeSizeTable[q.strEnc.version]-5, 2,
-		qrCodeSizeTable[q.strEnc.version]-3, 4, _paletteBlack)
-	// 左下角
-	q.drawRectangle(0, qrCodeSizeTable[q.strEnc.version]-7, 6,
-		qrCodeSizeTable[q.strEnc.version]-1, _paletteBlack)
-	q.drawSolidRectangle(2, qrCodeSizeTable[q.strEnc.version]-5, 4,
-		qrCodeSizeTable[q.strEnc.version]-3, _paletteBlack)
-}
-
-// timing patterns
-func (q *qrCode) drawTimingPatterns() {
-	// 水平
-	for i := 8; i < qrCodeSizeTable[q.strEnc.version]-8; {
-		q.drawPoint(i, 6, _paletteBlack)
-		i += 2
+	size := q.drawMatrix()
+	qz, ms := opt.quietZone(), opt.moduleSize()
+	n := (size + qz*2) * ms
+	img.Stride = n
+	img.Rect = image.Rect(0, 0, n, n)
+	if cap(img.Pix) < n*n {
+		img.Pix = make([]uint8, n*n)
+	} else {
+		img.Pix = img.Pix[:n*n]
+		for i := range img.Pix {
+			img.Pix[i] = 0
+		}
 	}
-	// 垂直
-	for i := 8; i < qrCodeSizeTable[q.strEnc.version]-8; {
-		q.drawPoint(6, i, _paletteBlack)
-		i += 2
+	// 所有区域颜色相同时不需要计算区域
+	same := true
+	for _, i := range index {
+		same = same && i == index[0]
 	}
-}
-
-// alignment patterns，
-func (q *qrCode) drawAlignmentPatterns() {
-	for _, r := range alignmentPatternTable[q.strEnc.version] {
-		q.drawRectangle(r.Min.X, r.Min.Y, r.Max.X, r.Max.Y, _paletteBlack)
-		q.drawPoint(r.Min.X+2, r.Min.Y+2, _paletteBlack)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if !q.pix.rows.Get(x, y) {
+				continue
+			}
+			i := index[0]
+			if !same {
+				i = index[moduleRegion(q.strEnc.version, x, y)]
+			}
+			for dy := 0; dy < ms; dy++ {
+				pix := img.Pix[((y+qz)*ms+dy)*n+(x+qz)*ms:]
+				for dx := 0; dx < ms; dx++ {
+					pix[dx] = i
+				}
+			}
+		}
 	}
+	return nil
 }
 
-// 左下角，格式信息上的一个黑点
-func (q *qrCode) drawBottomLeftPoint() {
-	// y=version*4+4+9，
-	q.drawPoint(8, int(q.strEnc.version)*4+13, _paletteBlack)
+// 画出最终的模块到q.pix.rows，返回边长
+func (q *qrCode) drawMatrix() int {
+	l := versionLayout(q.strEnc.version)
+	// 开始画图
+	q.pix.Copy(&l.pattern)
+	q.drawData(l)
+	q.drawVersionInformation(&q.pix, l)
+	q.mark(l)
+	return l.size
 }
 
-// 格式信息
-func (q *qrCode) drawFormatInformation() {
-	f := formatBitTable[q.strEnc.Level][q.markNum]
+// 格式信息，画在第i种mark之后的m上
+func (q *qrCode) drawFormatInformation(m *markMatrix, i int) {
+	f := formatBitTable[q.strEnc.Level][i]
 	idx := 0
 	// 左上角
 	for x := 0; x < 6; x++ {
 		if f[idx] == 1 {
-			q.drawPoint(x, 8, _paletteBlack)
+			m.Set(x, 8)
 		}
 		idx++
 	}
 	if f[idx] == 1 {
-		q.drawPoint(7, 8, _paletteBlack)
+		m.Set(7, 8)
 	}
 	idx++
 	if f[idx] == 1 {
-		q.drawPoint(8, 8, _paletteBlack)
+		m.Set(8, 8)
 	}
 	idx++
 	if f[idx] == 1 {
-		q.drawPoint(8, 7, _paletteBlack)
+		m.Set(8, 7)
 	}
 	idx++
 	for y := 5; y >= 0; y-- {
 		if f[idx] == 1 {
-			q.drawPoint(8, y, _paletteBlack)
+			m.Set(8, y)
 		}
 		idx++
 	}
@@ -1255,520 +1248,95 @@ func (q *qrCode) drawFormatInformation() {
 	// 左下角
 	for y := qrCodeSizeTable[q.strEnc.version] - 1; y > qrCodeSizeTable[q.strEnc.version]-8; y-- {
 		if f[idx] == 1 {
-			q.drawPoint(8, y, _paletteBlack)
+			m.Set(8, y)
 		}
 		idx++
 	}
 	// 右上角
 	for x := qrCodeSizeTable[q.strEnc.version] - 8; x <= qrCodeSizeTable[q.strEnc.version]-1; x++ {
 		if f[idx] == 1 {
-			q.drawPoint(x, 8, _paletteBlack)
+			m.Set(x, 8)
 		}
 		idx++
 	}
 }
 
-// 版本信息
-func (q *qrCode) drawVersionInformation() {
-	if q.strEnc.version < version7 {
+// 版本信息，不受mark影响，画在m上
+func (q *qrCode) drawVersionInformation(m *markMatrix, l *layout) {
+	if l.version < version7 {
 		return
 	}
-	ver := versionBitTable[q.strEnc.version]
-	x := qrCodeSizeTable[q.strEnc.version] - 11
-	y := qrCodeSizeTable[q.strEnc.version] - 8
-	idx := 0
-	for i := 0; i < 6; i++ {
-		for j := 0; j < 3; j++ {
-			if ver[idx] == 1 {
-				// 左下角
-				q.drawPoint(i, j+y, _paletteBlack)
-				// 右上角
-				q.drawPoint(i+x, j, _paletteBlack)
-			}
-			idx++
+	ver := versionBitTable[l.version]
+	for k, p := range l.versionInfo {
+		// ver[0]是最高位
+		if ver[versionInfoBits-1-k] == 1 {
+			m.Set(int(p[0][0]), int(p[0][1]))
+			m.Set(int(p[1][0]), int(p[1][1]))
 		}
 	}
 }
 
-// 数据
-func (q *qrCode) drawData() {
-	// 从右下角开始
-	x := qrCodeSizeTable[q.strEnc.version] - 1
-	y := qrCodeSizeTable[q.strEnc.version] - 1
-	// finder patterns，包括format区域
-	topCornerMaxY := 9
-	leftCornerMaxX := 9
-	leftBottomCornerMinY := y - 8
-	rightTopCornerMinX := x - 8
-	// version information area
-	topVersionMinX := rightTopCornerMinX - 3
-	bottomVersionMinY := leftBottomCornerMinY - 3
-	// 画
-	idx := 0
-	bit := byte(0b10000000)
-	char := q.eccEnc.data[idx]
-	up := true
-	drawPoint := func() bool {
-		if char&bit != 0 {
-			q.drawPoint(x, y, _paletteBlack)
-		}
-		bit >>= 1
-		if bit == 0 {
-			bit = 0b10000000
-			idx++
-			if idx == len(q.eccEnc.data) {
-				return false
-			}
-			char = q.eccEnc.data[idx]
-		}
-		return true
-	}
-Loop:
-	for {
-		if up {
-			// 右点
-			if !drawPoint() {
-				return
-			}
-			// 左点
-			x--
-			if !drawPoint() {
+// 数据，按照版本的放置顺序
+func (q *qrCode) drawData(l *layout) {
+	i := 0
+	for _, c := range q.eccEnc.data {
+		for bit := byte(0b10000000); bit != 0; bit >>= 1 {
+			// 剩下的模块是余数，白色
+			if i == len(l.order) {
 				return
 			}
-			// 顶部
-			if y == 0 {
-				x--
-				up = !up
-				continue Loop
-			}
-			// finder patterns
-			if y == topCornerMaxY {
-				// 右上
-				if x > rightTopCornerMinX {
-					x--
-					up = !up
-					continue Loop
-				}
-				// 左上
-				if x < leftCornerMaxX {
-					x--
-					// timing patterns，垂直
-					if x == timingPattern {
-						x--
-					}
-					up = !up
-					continue Loop
-				}
-				// 左下，不可能
-			}
-			// 上移
-			y--
-			// 检查alignment patterns
-			for _, r := range alignmentPatternTable[q.strEnc.version] {
-				if y == r.Max.Y {
-					// 右边向上
-					if x == r.Max.X {
-						x++
-						for i := 0; i < 5; i++ {
-							if !drawPoint() {
-								break Loop
-							}
-							y--
-							// timing patterns，水平
-							if y == timingPattern {
-								i++
-								y--
-							}
-						}
-						continue Loop
-					}
-					// 跳过矩形
-					if x >= r.Min.X && x < r.Max.X {
-						x++
-						y = r.Min.Y - 1
-						continue Loop
-					}
-					// 左边向上
-					if x == r.Min.X-1 {
-						for i := 0; i < 5; i++ {
-							if !drawPoint() {
-								break Loop
-							}
-							y--
-							// timing patterns，水平
-							if y == timingPattern {
-								i++
-								y--
-							}
-						}
-						x++
-						continue Loop
-					}
-				}
-			}
-			// timing patterns，水平
-			if y == timingPattern {
-				// 版本7以上
-				if q.strEnc.version >= version7 && x > topVersionMinX {
-					// 右上版本区左边向下
-					x -= 2
-					y = 0
-					for i := 0; i < 6; i++ {
-						if !drawPoint() {
-							break Loop
-						}
-						y++
-					}
-					x++
-					y++
-					up = !up
-					continue Loop
-				}
-				// 上移
-				y--
-			}
-			x++
-		} else {
-			// 右点
-			if !drawPoint() {
-				break Loop
+			if c&bit != 0 {
+				x, y := int(l.order[i][0]), int(l.order[i][1])
+				q.pix.Set(x, y)
 			}
-			// 左点
-			x--
-			if !drawPoint() {
-				break Loop
-			}
-			// 最左边
-			if x < timingPattern {
-				// 左下
-				if q.strEnc.version >= version7 {
-					if y == bottomVersionMinY {
-						x--
-						up = !up
-						continue Loop
-					}
-				} else {
-					if y == leftBottomCornerMinY {
-						x--
-						up = !up
-						continue Loop
-					}
-				}
-			}
-			// 下移
-			y++
-			// timing patterns，水平
-			if y == timingPattern {
-				x++
-				y++
-				continue Loop
-			}
-			// 检查alignment patterns
-			for _, r := range alignmentPatternTable[q.strEnc.version] {
-				if y == r.Min.Y {
-					// 右边向下
-					if x == r.Max.X {
-						x++
-						for i := 0; i < 5; i++ {
-							if !drawPoint() {
-								break Loop
-							}
-							y++
-							// timing patterns，水平
-							if y == timingPattern {
-								i++
-								y++
-							}
-						}
-						continue Loop
-					}
-					// 跳过矩形
-					if x >= r.Min.X && x < r.Max.X {
-						x++
-						y = r.Max.Y + 1
-						continue Loop
-					}
-					// 左边向下
-					if x == r.Min.X-1 {
-						for i := 0; i < 5; i++ {
-							if !drawPoint() {
-								break Loop
-							}
-							y++
-							// timing patterns，水平
-							if y == timingPattern {
-								i++
-								y++
-							}
-						}
-						x++
-						continue Loop
-					}
-				}
-			}
-			// 底部
-			if y == qrCodeSizeTable[q.strEnc.version] {
-				// 左移
-				x--
-				if x < leftCornerMaxX {
-					// 左下角
-					y = leftBottomCornerMinY
-				} else {
-					y--
-				}
-				// 向下
-				up = !up
-				continue Loop
-			}
-			x++
+			i++
 		}
 	}
 }
 
 // 对原始位图数据pix分别进行8种mark，最小评分的mark将作为最终的输出数据。
-func (q *qrCode) mark() {
-	// 以下是不能mark的区域
-	finderPatterns := [3]image.Rectangle{
-		{
-			Max: image.Point{X: 8, Y: 8},
-		}, // 左上
-		{
-			Min: image.Point{X: qrCodeSizeTable[q.strEnc.version] - 8},
-			Max: image.Point{X: qrCodeSizeTable[q.strEnc.version] - 1, Y: 8},
-		}, // 右上
-		{
-			Min: image.Point{Y: qrCodeSizeTable[q.strEnc.version] - 8},
-			Max: image.Point{X: 8, Y: qrCodeSizeTable[q.strEnc.version] - 1},
-		}, // 左下
-	}
-	alignmentPatterns := alignmentPatternTable[q.strEnc.version]
-	versionArea := [2]image.Rectangle{
-		{
-			Min: image.Point{X: finderPatterns[1].Min.X - 3},
-			Max: image.Point{X: finderPatterns[1].Min.X - 1, Y: 5},
-		}, // 右上
-		{
-			Min: image.Point{Y: finderPatterns[2].Min.Y - 3},
-			Max: image.Point{X: 5, Y: finderPatterns[1].Min.X - 1},
-		}, // 左下
-	}
-	// 得分
-	score, minScore := 0, 0xffffffff
-	// 生成mark图
-	for i := 0; i < maxMark; i++ {
-		for y := 0; y < len(q.pixXY); y++ {
-		Next:
-			for x := 0; x < len(q.pixXY[y]); x++ {
-				// finder patterns
-				for j := 0; j < len(finderPatterns); j++ {
-					if x >= finderPatterns[j].Min.X && x <= finderPatterns[j].Max.X &&
-						y >= finderPatterns[j].Min.Y && y <= finderPatterns[j].Max.Y {
-						q.markBuffXY[y][x] = q.pixXY[y][x]
-						continue Next
-					}
-				}
-				// timing patterns
-				if x == timingPattern || y == timingPattern {
-					q.markBuffXY[y][x] = q.pixXY[y][x]
-					continue Next
-				}
-				// alignment patterns
-				for j := 0; j < len(alignmentPatterns); j++ {
-					if x >= alignmentPatterns[j].Min.X && x <= alignmentPatterns[j].Max.X &&
-						y >= alignmentPatterns[j].Min.Y && y <= alignmentPatterns[j].Max.Y {
-						q.markBuffXY[y][x] = q.pixXY[y][x]
-						continue Next
-					}
-				}
-				// version information
-				if q.strEnc.version >= version7 {
-					for j := 0; j < len(versionArea); j++ {
-						if x >= versionArea[j].Min.X && x <= versionArea[j].Max.X &&
-							y >= versionArea[j].Min.Y && y <= versionArea[j].Max.Y {
-							q.markBuffXY[y][x] = q.pixXY[y][x]
-							continue Next
-						}
-					}
-				}
-				if markFunc[i](x, y) {
-					q.markBuffXY[y][x] = _paletteBlack ^ q.pixXY[y][x]
-					//q.markBuffXY[y][x] = _paletteBlack
-				} else {
-					q.markBuffXY[y][x] = _paletteWhite ^ q.pixXY[y][x]
-					//q.markBuffXY[y][x] = _paletteWhite
-				}
+func (q *qrCode) mark(l *layout) {
+	if q.parallelMarkVersion > 0 && int(l.version)+1 >= q.parallelMarkVersion {
+		q.markParallel(l)
+	} else {
+		// 得分
+		score, minScore := 0, 0xffffffff
+		for i := 0; i < maxMark; i++ {
+			q.markBuff.Mark(&q.pix, &l.data, i)
+			q.drawFormatInformation(&q.markBuff, i)
+			// 评估
+			score = q.markBuff.Score()
+			// 最小得分
+			if score < minScore {
+				minScore = score
+				q.markNum = i
 			}
 		}
-		// 评估
-		score = q.evaluation1() + q.evaluation2() + q.evaluation3() + q.evaluation4()
-		// 最小得分
-		if score < minScore {
-			minScore = score
-			q.markNum = i
-			t1 := q.markBuffXY
-			q.markBuffXY = q.markDataXY
-			q.markDataXY = t1
-			t2 := q.buffer.data
-			q.buffer.data = q.markData.data
-			q.markData.data = t2
-		}
 	}
 	// 最终的数据
-	for y := 0; y < len(q.markDataXY); y++ {
-		copy(q.pixXY[y], q.markDataXY[y])
-	}
+	markBits(&q.markBuff.rows, &q.pix.rows, &l.data.rows, &markRowTable[q.markNum])
+	q.pix.rows, q.markBuff.rows = q.markBuff.rows, q.pix.rows
+	q.drawFormatInformation(&q.pix, q.markNum)
 }
 
-// 找到5个连续颜色的点，+3分
-// 5个连续颜色的点之后，每多1个点+1分
-func (q *qrCode) evaluation1() int {
-	score := 0
-	var lastBlock uint8
-	var consecutive int
-	x, y := 0, 0
-	// 行
-	for ; y < len(q.markBuffXY); y++ {
-		consecutive = 0
-		lastBlock = q.markBuffXY[y][0]
-		for x = 1; x < len(q.markBuffXY[y]); x++ {
-			if q.markBuffXY[y][x] == lastBlock {
-				consecutive++
-				if consecutive == 5 {
-					score += 3
-				} else if consecutive > 5 {
-					score++
-				}
-			} else {
-				lastBlock = q.markBuffXY[y][x]
-				consecutive = 0
-			}
-		}
-	}
-	// 列
-	x = 0
-	for ; x < len(q.markBuffXY[0]); x++ {
-		consecutive = 0
-		lastBlock = q.markBuffXY[0][x]
-		for y = 1; y < len(q.markBuffXY); y++ {
-			if q.markBuffXY[y][x] == lastBlock {
-				consecutive++
-				if consecutive == 5 {
-					score += 3
-				} else if consecutive > 5 {
-					score++
-				}
-			} else {
-				lastBlock = q.markBuffXY[y][x]
-				consecutive = 0
-			}
-		}
-	}
-	return score
-}
-
-// 找到相同颜色的最小矩形（2*2），+3分
-func (q *qrCode) evaluation2() int {
-	score := 0
-	x, y := 0, 0
-	for ; y < len(q.markBuffXY)-1; y++ {
-		for ; x < len(q.markBuffXY[y])-1; x++ {
-			if q.markBuffXY[y][x] == q.markBuffXY[y][x+1] &&
-				q.markBuffXY[y][x] == q.markBuffXY[y+1][x] &&
-				q.markBuffXY[y][x] == q.markBuffXY[y+1][x+1] {
-				score += 3
-			}
-		}
-	}
-	return score
-}
-
-// 找到[10111010000]或者[00001011101]，+40分
-func (q *qrCode) evaluation3() int {
-	score := 0
-	x, y, n, m := 0, 0, 0, 0
-	o := true
-	// 行
-	m = len(q.markBuffXY[0]) - len(evaluation3Bytes[0]) - 1
-	for ; y < len(q.markBuffXY); y++ {
-		for x < m {
-			o = true
-			n = x
-			for _, c := range evaluation3Bytes[0] {
-				if c != q.markBuffXY[y][n] {
-					o = false
-					break
-				}
-				n++
-			}
-			if o {
-				score += 40
-				x += len(evaluation3Bytes[0])
-			} else {
-				x++
-			}
-		}
-		x = 0
-	}
-	// 列
-	m = len(q.markBuffXY) - len(evaluation3Bytes[1]) - 1
-	x = 0
-	for ; x < len(q.markBuffXY[0]); x++ {
-		y = 0
-		for y < m {
-			o = true
-			n = y
-			for _, c := range evaluation3Bytes[1] {
-				if c != q.markBuffXY[n][x] {
-					o = false
-					break
-				}
-				n++
-			}
-			if o {
-				score += 40
-				y += len(evaluation3Bytes[1])
-			} else {
-				y++
-			}
-		}
+// 8种mark在多个goroutine中评分，得分相同时使用编号小的，和顺序评分的结果一样
+func (q *qrCode) markParallel(l *layout) {
+	var score [maxMark]int
+	var wg sync.WaitGroup
+	wg.Add(maxMark)
+	for i := 0; i < maxMark; i++ {
+		go func(i int) {
+			defer wg.Done()
+			q.markBuffs[i].Mark(&q.pix, &l.data, i)
+			q.drawFormatInformation(&q.markBuffs[i], i)
+			score[i] = q.markBuffs[i].Score()
+		}(i)
 	}
-	return score
-}
-
-// 计算黑点的百分比，（黑点总数/总点）*100，然后得到前后两个5的倍数。
-// 这两个数-50，再求绝对值，然后除以5。
-// 得到的最小商*10，就是最终得分
-func (q *qrCode) evaluation4() int {
-	// 黑点的总数
-	n := 0
-	for i := 0; i < len(q.buffer.data); i++ {
-		if q.buffer.data[i] == _paletteBlack {
-			n++
+	wg.Wait()
+	q.markNum = 0
+	for i := 1; i < maxMark; i++ {
+		if score[i] < score[q.markNum] {
+			q.markNum = i
 		}
 	}
-	// 百分比
-	m := int(math.Ceil(float64(n) / float64(len(q.buffer.data)) * 100))
-	// 得到前后两个5的倍数
-	n1 := m / 5 * 5
-	n2 := (m/5 + 1) * 5
-	// 这两个数-50，再求绝对值
-	if n1 >= 50 {
-		n1 -= 50
-	} else {
-		n1 = 50 - n1
-	}
-	if n2 >= 50 {
-		n2 -= 50
-	} else {
-		n2 = 50 - n2
-	}
-	// 除以5
-	n1 /= 5
-	n2 /= 5
-	// 最小商*10
-	if n1 > n2 {
-		return n2 * 10
-	} else {
-		return n1 * 10
-	}
 }
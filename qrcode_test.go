@@ -2,13 +2,44 @@ package qrcode
 
 import (
 	"github.com/skip2/go-qrcode"
+	"image"
+	"image/color"
+	"reflect"
+	"strings"
 	"testing"
 )
 
 var (
 	testStr = "你多1231行上sdfsd岛咖东方航空"
+	// 版本20
+	testStrV20 = strings.Repeat("hello, world! ", 60)[:800]
+	// 版本40
+	testStrV40 = strings.Repeat("0123456789", 700)
 )
 
+func TestParallelMark(t *testing.T) {
+	q1, q2 := new(qrCode), new(qrCode)
+	q1.init()
+	q2.init()
+	for _, s := range []string{testStr, testStrV20, testStrV40} {
+		for l := LevelL; l < maxLevel; l++ {
+			err1 := q1.EncodeOptions(s, &Options{Level: l})
+			err2 := q2.EncodeOptions(s, &Options{Level: l, ParallelMarkVersion: 1})
+			if err1 != nil || err2 != nil {
+				if (err1 == nil) != (err2 == nil) {
+					t.Fatalf("error mismatch %v %v", err1, err2)
+				}
+				continue
+			}
+			q1.drawMatrix()
+			q2.drawMatrix()
+			if q1.markNum != q2.markNum || !reflect.DeepEqual(q1.pix.rows.bits, q2.pix.rows.bits) {
+				t.Fatalf("parallel mark differs, level %d, length %d", l, len(s))
+			}
+		}
+	}
+}
+
 func BenchmarkImageMy(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
@@ -17,6 +48,57 @@ func BenchmarkImageMy(b *testing.B) {
 	}
 }
 
+func BenchmarkEncoder(b *testing.B) {
+	var e Encoder
+	var img image.Paletted
+	opt := &Options{ModuleSize: 4, Colors: &Colors{Finder: Solid{color.RGBA{0, 0, 128, 255}}}}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.EncodeInto(&img, testStr, opt)
+	}
+}
+
+func BenchmarkImageV20(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Image(testStrV20, LevelL)
+	}
+}
+
+func BenchmarkImageV40(b *testing.B) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Image(testStrV40, LevelL)
+	}
+}
+
+func BenchmarkImageV40Parallel(b *testing.B) {
+	opt := &Options{ParallelMarkVersion: 30}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		newMatrix(testStrV40, opt)
+	}
+}
+
+func BenchmarkEccEncodeV40(b *testing.B) {
+	// 版本40，LevelH，81个块
+	data := make([]byte, errorCorrectionTable[version40][LevelH].TotalBytes)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	var e eccEncoder
+	e.buff = new(buffer)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e.Encode(data, version40, LevelH)
+	}
+}
+
 func BenchmarkImageSkip2(b *testing.B) {
 	b.ReportAllocs()
 	b.ResetTimer()
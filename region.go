@@ -0,0 +1,58 @@
+package qrcode
+
+// 模块所在的区域
+type Region int
+
+const (
+	RegionData      Region = iota // 数据和纠错码
+	RegionFinder                  // finder patterns，包括分隔符
+	RegionAlignment               // alignment patterns
+	RegionTiming                  // timing patterns
+	RegionFormat                  // 格式信息，版本信息和左下角的黑点
+	maxRegion
+)
+
+var (
+	regionString = [maxRegion]string{
+		"data", "finder", "alignment", "timing", "format",
+	}
+)
+
+func (r Region) String() string {
+	if r < 0 || r >= maxRegion {
+		return "unknown"
+	}
+	return regionString[r]
+}
+
+// 判断版本v的(x,y)模块所在的区域，RegionData以外的区域都不能mark
+func moduleRegion(v version, x, y int) Region {
+	size := qrCodeSizeTable[v]
+	// finder patterns，包括格式信息
+	if (x <= 8 && y <= 8) || (x >= size-8 && y <= 8) || (x <= 8 && y >= size-8) {
+		if x == 8 || y == 8 {
+			if x != timingPattern && y != timingPattern {
+				return RegionFormat
+			}
+			return RegionTiming
+		}
+		return RegionFinder
+	}
+	// timing patterns
+	if x == timingPattern || y == timingPattern {
+		return RegionTiming
+	}
+	// alignment patterns
+	for _, r := range alignmentPatternTable[v] {
+		if x >= r.Min.X && x <= r.Max.X && y >= r.Min.Y && y <= r.Max.Y {
+			return RegionAlignment
+		}
+	}
+	// version information
+	if v >= version7 {
+		if (x >= size-11 && x <= size-9 && y <= 5) || (y >= size-11 && y <= size-9 && x <= 5) {
+			return RegionFormat
+		}
+	}
+	return RegionData
+}
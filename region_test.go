@@ -0,0 +1,44 @@
+package qrcode
+
+import (
+	"image"
+	"testing"
+)
+
+// 和原来mark中的矩形判断比较，不能mark的区域没有变化
+func TestModuleRegion(t *testing.T) {
+	in := func(r image.Rectangle, x, y int) bool {
+		return x >= r.Min.X && x <= r.Max.X && y >= r.Min.Y && y <= r.Max.Y
+	}
+	for v := version1; v < maxVersion; v++ {
+		size := qrCodeSizeTable[v]
+		finder := [3]image.Rectangle{
+			{Max: image.Point{X: 8, Y: 8}},
+			{Min: image.Point{X: size - 8}, Max: image.Point{X: size - 1, Y: 8}},
+			{Min: image.Point{Y: size - 8}, Max: image.Point{X: 8, Y: size - 1}},
+		}
+		versionArea := [2]image.Rectangle{
+			{Min: image.Point{X: size - 11}, Max: image.Point{X: size - 9, Y: 5}},
+			{Min: image.Point{Y: size - 11}, Max: image.Point{X: 5, Y: size - 9}},
+		}
+		for y := 0; y < size; y++ {
+			for x := 0; x < size; x++ {
+				fixed := x == timingPattern || y == timingPattern
+				for _, r := range finder {
+					fixed = fixed || in(r, x, y)
+				}
+				for _, r := range alignmentPatternTable[v] {
+					fixed = fixed || in(*r, x, y)
+				}
+				if v >= version7 {
+					for _, r := range versionArea {
+						fixed = fixed || in(r, x, y)
+					}
+				}
+				if fixed != (moduleRegion(v, x, y) != RegionData) {
+					t.Fatalf("version %d module (%d,%d) got %s", v+1, x, y, moduleRegion(v, x, y))
+				}
+			}
+		}
+	}
+}
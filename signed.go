@@ -0,0 +1,91 @@
+package qrcode
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+)
+
+const (
+	// 签名数据的版本，头部字节的低4位
+	signedVersion = 1
+	// 头部字节的压缩标志
+	signedCompress = 0x10
+)
+
+var (
+	// 签名不正确，数据被篡改或者公钥不对
+	ErrSignature = errors.New("signed payload signature mismatch")
+)
+
+// Ed25519签名的数据，格式是1个字节的头部+消息+64个字节的签名，签名包括头部
+type Signed struct {
+	Message  []byte             // 原始消息
+	Key      ed25519.PrivateKey // 签名的私钥
+	Base45   bool               // 编码成Base45，使用字母数字模式，否则是字节模式的二进制
+	Compress bool               // 消息先用zlib压缩
+}
+
+func (s *Signed) Text() (string, error) {
+	if len(s.Key) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("invalid ed25519 private key size <%d>", len(s.Key))
+	}
+	h := byte(signedVersion)
+	msg := s.Message
+	if s.Compress {
+		h |= signedCompress
+		msg = zlibCompress(msg)
+	}
+	b := make([]byte, 0, 1+len(msg)+ed25519.SignatureSize)
+	b = append(b, h)
+	b = append(b, msg...)
+	b = append(b, ed25519.Sign(s.Key, b)...)
+	if s.Base45 {
+		return EncodeBase45(b), nil
+	}
+	return string(b), nil
+}
+
+// 验证扫码得到的签名数据，返回原始消息，签名不正确返回ErrSignature。
+// 很多扫码器把字节模式的数据当作Latin-1，返回UTF-8字符串，也可以验证
+func VerifySigned(data string, key ed25519.PublicKey) ([]byte, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid ed25519 public key size <%d>", len(key))
+	}
+	b := []byte(data)
+	// 二进制的头部是控制字符，不是Base45的字符
+	if len(b) > 0 && (b[0] == signedVersion || b[0] == signedVersion|signedCompress) {
+		msg, err := verifySigned(b, key)
+		if err == ErrSignature {
+			// 有大于0x7F的字符时，Latin-1的字节比UTF-8少
+			if l, ok := latin1Bytes(data); ok && len(l) != len(b) {
+				return verifySigned(l, key)
+			}
+		}
+		return msg, err
+	}
+	b, err := DecodeBase45(data)
+	if err != nil {
+		return nil, err
+	}
+	return verifySigned(b, key)
+}
+
+// 验证头部+消息+签名的二进制数据
+func verifySigned(b []byte, key ed25519.PublicKey) ([]byte, error) {
+	if len(b) < 1+ed25519.SignatureSize {
+		return nil, fmt.Errorf("signed payload length <%d> too small", len(b))
+	}
+	if b[0]&^signedCompress != signedVersion {
+		return nil, fmt.Errorf("unsupported signed payload header <%02X>", b[0])
+	}
+	n := len(b) - ed25519.SignatureSize
+	if !ed25519.Verify(key, b[:n], b[n:]) {
+		return nil, ErrSignature
+	}
+	msg := b[1:n]
+	if b[0]&signedCompress != 0 {
+		return zlibDecompress(msg)
+	}
+	return append([]byte(nil), msg...), nil
+}
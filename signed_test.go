@@ -0,0 +1,84 @@
+package qrcode
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"testing"
+)
+
+func TestSigned(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	pub := key.Public().(ed25519.PublicKey)
+	msg := []byte(`{"ticket":"A-0001","seat":"12F","event":"concert"}`)
+	for _, s := range []*Signed{
+		{Message: msg, Key: key},
+		{Message: msg, Key: key, Base45: true},
+		{Message: msg, Key: key, Base45: true, Compress: true},
+	} {
+		str, err := s.Text()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.Base45 && analysisMode(str) != alphanumericMode {
+			t.Fatalf("expect alphanumeric mode <%s>", str)
+		}
+		m, err := VerifySigned(str, pub)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(m, msg) {
+			t.Fatalf("verify got <%s>", m)
+		}
+		if !s.Base45 {
+			// 扫码器当作Latin-1返回的UTF-8字符串
+			r := make([]rune, len(str))
+			for i := 0; i < len(str); i++ {
+				r[i] = rune(str[i])
+			}
+			if string(r) == str {
+				t.Fatal("expect bytes greater than 0x7F")
+			}
+			if m, err = VerifySigned(string(r), pub); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(m, msg) {
+				t.Fatalf("verify latin-1 got <%s>", m)
+			}
+		}
+		// 修改消息
+		b := []byte(str)
+		b[len(b)/2] ^= 1
+		if _, err = VerifySigned(string(b), pub); err == nil {
+			t.Fatal("expect error for tampered data")
+		}
+	}
+	// 不同的消息，Base45的第一个字符可能是任意字符
+	for i := 0; i < 256; i++ {
+		m := []byte(fmt.Sprintf("%cmessage %d", i, i))
+		for _, s := range []*Signed{
+			{Message: m, Key: key},
+			{Message: m, Key: key, Compress: true},
+			{Message: m, Key: key, Base45: true},
+			{Message: m, Key: key, Base45: true, Compress: true},
+		} {
+			str, err := s.Text()
+			if err != nil {
+				t.Fatal(err)
+			}
+			v, err := VerifySigned(str, pub)
+			if err != nil {
+				t.Fatalf("verify <%s>: %v", str, err)
+			}
+			if !bytes.Equal(v, m) {
+				t.Fatalf("verify got <%s>", v)
+			}
+		}
+	}
+	str, _ := (&Signed{Message: msg, Key: key}).Text()
+	b := []byte(str)
+	b[5] ^= 1
+	if _, err := VerifySigned(string(b), pub); err != ErrSignature {
+		t.Fatalf("expect ErrSignature, got %v", err)
+	}
+}
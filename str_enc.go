@@ -126,20 +126,25 @@ func (e *strEncoder) encStrLength() {
 	}
 }
 
-// 调整编码的数据大小
+// 终止符和填充字节，数据正好是版本的数据码字个数
 func (e *strEncoder) appendPadBytes() {
-	if len(e.bitD) < errorCorrectionTable[e.version][e.Level].TotalBytes {
-		if e.bitN < 4 {
-			e.bitD = append(e.bitD, 0)
+	total := errorCorrectionTable[e.version][e.Level].TotalBytes
+	if e.bitN == 8 {
+		// 最后一个字节是空的，是终止符和补齐的0，容量满了就不需要
+		if len(e.bitD) > total {
+			e.bitD = e.bitD[:total]
 		}
+	} else if e.bitN < 4 && len(e.bitD) < total {
+		// 剩余的bit不够4个0的终止符
+		e.bitD = append(e.bitD, 0)
 	}
 	e.bitN = 0
 	for {
-		if len(e.bitD) >= errorCorrectionTable[e.version][e.Level].TotalBytes {
+		if len(e.bitD) >= total {
 			return
 		}
 		e.bitD = append(e.bitD, 236)
-		if len(e.bitD) >= errorCorrectionTable[e.version][e.Level].TotalBytes {
+		if len(e.bitD) >= total {
 			return
 		}
 		e.bitD = append(e.bitD, 17)
@@ -0,0 +1,220 @@
+package qrcode
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// 二维码的边长，单位毫米
+	swissQRSize = 46
+	// 瑞士十字的边长，单位毫米
+	swissCrossSize = 7
+	// 数据最多997个字符
+	swissQRMaxChars = 997
+)
+
+var (
+	// QRR参考号递归模10的表
+	mod10Table = [10]int{0, 9, 4, 6, 8, 2, 7, 1, 3, 5}
+)
+
+// 结构化地址
+type SwissAddress struct {
+	Name           string // 名称，最多70个字符
+	Street         string // 街道，最多70个字符，可以为空
+	BuildingNumber string // 门牌号，最多16个字符，可以为空
+	PostalCode     string // 邮编，最多16个字符
+	Town           string // 城市，最多35个字符
+	Country        string // ISO 3166-1国家代码，2个字母
+}
+
+// 7行地址，S是结构化地址
+func (a *SwissAddress) lines(name string) ([]string, error) {
+	for _, f := range []struct {
+		value string
+		max   int
+		empty bool
+	}{
+		{a.Name, 70, false},
+		{a.Street, 70, true},
+		{a.BuildingNumber, 16, true},
+		{a.PostalCode, 16, false},
+		{a.Town, 35, false},
+	} {
+		n := utf8.RuneCountInString(f.value)
+		if (n == 0 && !f.empty) || n > f.max || strings.ContainsAny(f.value, "\r\n") {
+			return nil, fmt.Errorf("invalid swiss qr-bill %s address <%s>", name, f.value)
+		}
+	}
+	if len(a.Country) != 2 || !isUpperLetters(a.Country) {
+		return nil, fmt.Errorf("invalid swiss qr-bill %s country <%s>", name, a.Country)
+	}
+	return []string{"S", a.Name, a.Street, a.BuildingNumber, a.PostalCode, a.Town, a.Country}, nil
+}
+
+// 瑞士QR账单，SPC 0200
+type SwissQRBill struct {
+	IBAN               string        // 收款账户，CH或者LI开头，可以包含空格，QR-IBAN必须使用QRR参考号
+	Creditor           SwissAddress  // 收款人
+	Amount             string        // 金额，例如"12.30"，范围0.01-999999999.99，可以为空
+	Currency           string        // CHF或者EUR
+	Debtor             *SwissAddress // 付款人，可以为nil
+	Reference          string        // 参考号，27位数字是QRR，RF开头是SCOR，可以为空
+	Message            string        // 非结构化信息，最多140个字符
+	BillInformation    string        // 账单信息，最多140个字符
+	AlternativeSchemes []string      // 其他支付方式，最多2个，每个最多100个字符
+}
+
+// 生成字符串
+func (b *SwissQRBill) Text() (string, error) {
+	iban := strings.ToUpper(strings.ReplaceAll(b.IBAN, " ", ""))
+	if len(iban) != 21 || (iban[:2] != "CH" && iban[:2] != "LI") || !validIBAN(iban) {
+		return "", fmt.Errorf("invalid swiss qr-bill iban <%s>", b.IBAN)
+	}
+	lines := []string{"SPC", "0200", "1", iban}
+	creditor, err := b.Creditor.lines("creditor")
+	if err != nil {
+		return "", err
+	}
+	lines = append(lines, creditor...)
+	// 最终收款人，保留
+	lines = append(lines, "", "", "", "", "", "", "")
+	if b.Amount != "" && !validEPCAmount(b.Amount) {
+		return "", fmt.Errorf("invalid swiss qr-bill amount <%s>", b.Amount)
+	}
+	if b.Currency != "CHF" && b.Currency != "EUR" {
+		return "", fmt.Errorf("invalid swiss qr-bill currency <%s>", b.Currency)
+	}
+	lines = append(lines, b.Amount, b.Currency)
+	if b.Debtor != nil {
+		debtor, err := b.Debtor.lines("debtor")
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, debtor...)
+	} else {
+		lines = append(lines, "", "", "", "", "", "", "")
+	}
+	// 参考号
+	ref := strings.ToUpper(strings.ReplaceAll(b.Reference, " ", ""))
+	qrIBAN := isQRIBAN(iban)
+	switch {
+	case ref == "":
+		if qrIBAN {
+			return "", fmt.Errorf("swiss qr-iban requires a QRR reference")
+		}
+		lines = append(lines, "NON", "")
+	case strings.HasPrefix(ref, "RF"):
+		if qrIBAN || !validCreditorReference(ref) {
+			return "", fmt.Errorf("invalid swiss qr-bill SCOR reference <%s>", b.Reference)
+		}
+		lines = append(lines, "SCOR", ref)
+	default:
+		if !qrIBAN || !validQRReference(ref) {
+			return "", fmt.Errorf("invalid swiss qr-bill QRR reference <%s>", b.Reference)
+		}
+		lines = append(lines, "QRR", ref)
+	}
+	if utf8.RuneCountInString(b.Message) > 140 || strings.ContainsAny(b.Message, "\r\n") {
+		return "", fmt.Errorf("invalid swiss qr-bill message <%s>", b.Message)
+	}
+	if utf8.RuneCountInString(b.BillInformation) > 140 || strings.ContainsAny(b.BillInformation, "\r\n") {
+		return "", fmt.Errorf("invalid swiss qr-bill information <%s>", b.BillInformation)
+	}
+	lines = append(lines, b.Message, "EPD")
+	if len(b.AlternativeSchemes) > 2 {
+		return "", fmt.Errorf("swiss qr-bill supports at most 2 alternative schemes")
+	}
+	if b.BillInformation != "" || len(b.AlternativeSchemes) > 0 {
+		lines = append(lines, b.BillInformation)
+	}
+	for _, s := range b.AlternativeSchemes {
+		if utf8.RuneCountInString(s) > 100 || strings.ContainsAny(s, "\r\n") {
+			return "", fmt.Errorf("invalid swiss qr-bill alternative scheme <%s>", s)
+		}
+		lines = append(lines, s)
+	}
+	s := strings.Join(lines, "\n")
+	if utf8.RuneCountInString(s) > swissQRMaxChars {
+		return "", fmt.Errorf("swiss qr-bill data length <%d> too lager", utf8.RuneCountInString(s))
+	}
+	return s, nil
+}
+
+// 生成46x46毫米的二维码图像，中间是7x7毫米的瑞士十字，dpi是每英寸的像素，
+// 图像不包括空白边，账单的付款部分需要留出至少5毫米的空白
+func (b *SwissQRBill) Image(dpi int) (image.Image, error) {
+	if dpi <= 0 {
+		return nil, fmt.Errorf("invalid dpi <%d>", dpi)
+	}
+	s, err := b.Text()
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMatrix(s, &Options{Level: LevelM})
+	if err != nil {
+		return nil, err
+	}
+	mm := func(f float64) int {
+		return int(math.Round(f * float64(dpi) / 25.4))
+	}
+	n := mm(swissQRSize)
+	img := image.NewPaletted(image.Rect(0, 0, n, n), _palette)
+	// 模块，边界按比例取整，保证总边长是46毫米
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if m.Dark(x, y) {
+				r := image.Rect(x*n/m.size, y*n/m.size, (x+1)*n/m.size, (y+1)*n/m.size)
+				draw.Draw(img, r, image.Black, image.Point{}, draw.Src)
+			}
+		}
+	}
+	// 瑞士十字，白色边框，黑色方块，白色十字
+	c := float64(swissQRSize) / 2
+	square := func(half float64) image.Rectangle {
+		return image.Rect(mm(c-half), mm(c-half), mm(c+half), mm(c+half))
+	}
+	draw.Draw(img, square(swissCrossSize/2.0), image.White, image.Point{}, draw.Src)
+	draw.Draw(img, square(swissCrossSize/2.0-0.5), image.Black, image.Point{}, draw.Src)
+	// 十字占黑色方块的20/32，臂宽6/32
+	arm, width := (swissCrossSize-1)*10/32.0, (swissCrossSize-1)*3/32.0
+	draw.Draw(img, image.Rect(mm(c-arm), mm(c-width), mm(c+arm), mm(c+width)), image.White, image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(mm(c-width), mm(c-arm), mm(c+width), mm(c+arm)), image.White, image.Point{}, draw.Src)
+	return img, nil
+}
+
+// 是否QR-IBAN，银行代码在30000-31999
+func isQRIBAN(iban string) bool {
+	iid := iban[4:9]
+	return iid >= "30000" && iid <= "31999"
+}
+
+// 检查QRR参考号，27位数字，最后一位是递归模10的校验位
+func validQRReference(s string) bool {
+	if len(s) != 27 || !isDigits(s) {
+		return false
+	}
+	carry := 0
+	for i := 0; i < 26; i++ {
+		carry = mod10Table[(carry+int(s[i]-'0'))%10]
+	}
+	return (10-carry)%10 == int(s[26]-'0')
+}
+
+// 检查ISO 11649参考号，RF加2位校验位，最多21个字符，与IBAN使用相同的模97校验
+func validCreditorReference(s string) bool {
+	if len(s) < 5 || len(s) > 25 || !isDigits(s[2:4]) {
+		return false
+	}
+	for i := 4; i < len(s); i++ {
+		if !isUpperAlnum(s[i]) {
+			return false
+		}
+	}
+	return mod97(s[4:]+s[:4]) == 1
+}
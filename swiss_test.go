@@ -0,0 +1,60 @@
+package qrcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSwissQRBill(t *testing.T) {
+	b := &SwissQRBill{
+		IBAN: "CH44 3199 9123 0008 8901 2",
+		Creditor: SwissAddress{
+			Name:           "Robert Schneider AG",
+			Street:         "Rue du Lac",
+			BuildingNumber: "1268",
+			PostalCode:     "2501",
+			Town:           "Biel",
+			Country:        "CH",
+		},
+		Amount:    "1949.75",
+		Currency:  "CHF",
+		Reference: "21 00000 00003 13947 14300 09017",
+		Message:   "Auftrag vom 15.06.2020",
+	}
+	s, err := b.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(s, "\n")
+	if len(lines) != 31 || lines[3] != "CH4431999123000889012" || lines[27] != "QRR" || lines[30] != "EPD" {
+		t.Fatalf("unexpected swiss qr-bill text <%q>", s)
+	}
+	img, err := b.Image(300)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 543 {
+		t.Fatalf("image size <%d> not 46mm at 300dpi", img.Bounds().Dx())
+	}
+	// QR-IBAN必须使用QRR
+	b.Reference = "RF18 5390 0754 7034"
+	if _, err = b.Text(); err == nil {
+		t.Fatal("expect reference type error")
+	}
+	b.IBAN = "CH93 0076 2011 6238 5295 7"
+	if _, err = b.Text(); err != nil {
+		t.Fatal(err)
+	}
+	// 不能注入多余的行
+	for _, c := range []SwissQRBill{
+		{Message: "hi\nEPD"},
+		{Message: "hi\rEPD"},
+		{BillInformation: "//S1\nEPD"},
+		{AlternativeSchemes: []string{"eBill\nEPD"}},
+	} {
+		c.IBAN, c.Creditor, c.Currency = b.IBAN, b.Creditor, b.Currency
+		if _, err = c.Text(); err == nil {
+			t.Fatalf("<%+v> expect error", c)
+		}
+	}
+}
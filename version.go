@@ -97,10 +97,10 @@ var (
 				2238, 2369, 2506, 2632, 2780, 2894, 3054, 3220, 3391,
 			},
 			{
-				20, 38, 61, 90, 122, 154, 178, 221, 262, 311, 366,
-				419, 483, 528, 600, 656, 734, 816, 909, 970, 1035,
-				1134, 1248, 1326, 1451, 1542, 1637, 1732, 1839, 1994, 2113,
-				2238, 2369, 2506, 2632, 2780, 2894, 3054, 3220, 3391,
+				14, 26, 42, 62, 84, 106, 122, 152, 180, 213, 251,
+				287, 331, 362, 412, 450, 504, 560, 624, 666, 711,
+				779, 857, 911, 997, 1059, 1125, 1190, 1264, 1370, 1452,
+				1538, 1628, 1722, 1809, 1911, 1989, 2099, 2213, 2331,
 			},
 			{
 				8, 16, 26, 38, 52, 65, 75, 93, 111, 131, 155,
@@ -184,6 +184,9 @@ func initQRCodeSizeTable() {
 
 // 判断编码版本
 func analysisVersion(str string, level Level, mode mode) (version, error) {
+	if level < LevelL || level >= maxLevel {
+		return maxVersion, fmt.Errorf("invalid level <%d>", level)
+	}
 	for i, a := range strMaxLenTable[level][mode] {
 		if len(str) <= a {
 			return version(i), nil
@@ -0,0 +1,98 @@
+package qrcode
+
+import (
+	"image"
+	"io"
+	"strings"
+	"testing"
+)
+
+// 每种模式的最大长度和纠错表的数据码字个数一致
+func TestStrMaxLen(t *testing.T) {
+	var e strEncoder
+	e.bitD = make([]byte, 1)
+	e.buff = new(buffer)
+	chars := [maxMode]string{"1", "A", "a"}
+	for l := LevelL; l < maxLevel; l++ {
+		for v := version1; v < maxVersion; v++ {
+			bits := errorCorrectionTable[v][l].TotalBytes*8 - 4
+			// 字符数的bit数
+			count := [maxMode]int{10, 9, 8, 8}
+			if v >= version27 {
+				count = [maxMode]int{14, 13, 16, 12}
+			} else if v >= version10 {
+				count = [maxMode]int{12, 11, 16, 10}
+			}
+			var want [maxMode]int
+			for md := numericMode; md < maxMode; md++ {
+				n := bits - count[md]
+				switch md {
+				case numericMode:
+					want[md] = n / 10 * 3
+					if n%10 >= 7 {
+						want[md] += 2
+					} else if n%10 >= 4 {
+						want[md]++
+					}
+				case alphanumericMode:
+					want[md] = n / 11 * 2
+					if n%11 >= 6 {
+						want[md]++
+					}
+				case byteMode:
+					want[md] = n / 8
+				case kanJiMode:
+					want[md] = n / 13
+				}
+				if strMaxLenTable[l][md][v] != want[md] {
+					t.Fatalf("level %d mode %d version %d got %d, want %d", l, md, v+1, strMaxLenTable[l][md][v], want[md])
+				}
+			}
+			// 最大长度的字符串正好填满数据码字
+			for md := numericMode; md < kanJiMode; md++ {
+				s := strings.Repeat(chars[md], want[md])
+				if err := e.Encode(s, l); err != nil {
+					t.Fatal(err)
+				}
+				if e.version != v || e.mode != md || len(e.bitD) != errorCorrectionTable[v][l].TotalBytes {
+					t.Fatalf("level %d mode %d version %d got version %d, %d bytes", l, md, v+1, e.version+1, len(e.bitD))
+				}
+			}
+		}
+	}
+}
+
+// 所有输出都检查纠错等级
+func TestInvalidLevel(t *testing.T) {
+	var e Encoder
+	var img image.Paletted
+	for _, l := range []Level{-1, maxLevel, 7} {
+		opt := Options{Level: l}
+		if _, err := Image(testStr, l); err == nil {
+			t.Fatalf("Image level %d expect error", l)
+		}
+		if _, err := StyledImage(testStr, &opt); err == nil {
+			t.Fatalf("StyledImage level %d expect error", l)
+		}
+		if e.EncodeInto(&img, testStr, &opt) == nil {
+			t.Fatalf("EncodeInto level %d expect error", l)
+		}
+		if _, _, err := e.AppendMatrix(nil, testStr, &opt); err == nil {
+			t.Fatalf("AppendMatrix level %d expect error", l)
+		}
+		if PDF(io.Discard, testStr, &PDFOptions{Options: opt}) == nil {
+			t.Fatalf("PDF level %d expect error", l)
+		}
+		if EPS(io.Discard, testStr, &EPSOptions{Options: opt}) == nil {
+			t.Fatalf("EPS level %d expect error", l)
+		}
+		for _, native := range []bool{false, true} {
+			if ZPL(io.Discard, testStr, &ZPLOptions{Options: opt, Native: native}) == nil {
+				t.Fatalf("ZPL level %d native %v expect error", l, native)
+			}
+			if ESCPOS(io.Discard, testStr, &ESCPOSOptions{Options: opt, Native: native}) == nil {
+				t.Fatalf("ESCPOS level %d native %v expect error", l, native)
+			}
+		}
+	}
+}
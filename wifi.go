@@ -0,0 +1,190 @@
+package qrcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Wi-Fi的认证方式
+type WiFiAuth int
+
+const (
+	WiFiNoPass WiFiAuth = iota // 没有密码
+	WiFiWEP                    // WEP
+	WiFiWPA                    // WPA
+	WiFiWPA2                   // WPA2，与WPA使用相同的T:WPA，大多数扫码程序只识别WPA
+	WiFiWPA3                   // WPA3，T:SAE
+	maxWiFiAuth
+)
+
+var (
+	wifiAuthString = [maxWiFiAuth]string{
+		"nopass", "WEP", "WPA", "WPA", "SAE",
+	}
+)
+
+func (a WiFiAuth) String() string {
+	if a < 0 || a >= maxWiFiAuth {
+		return "unknown"
+	}
+	return wifiAuthString[a]
+}
+
+// Wi-Fi网络配置，WIFI:T:WPA;S:ssid;P:password;H:true;;
+type WiFi struct {
+	SSID     string   // 网络名称
+	Auth     WiFiAuth // 认证方式
+	Password string   // 密码，WiFiNoPass时忽略
+	Hidden   bool     // 是否隐藏的网络
+}
+
+// 生成配置字符串
+func (w *WiFi) Text() (string, error) {
+	if w.SSID == "" {
+		return "", fmt.Errorf("wifi ssid is empty")
+	}
+	if w.Auth < 0 || w.Auth >= maxWiFiAuth {
+		return "", fmt.Errorf("invalid wifi auth <%d>", w.Auth)
+	}
+	if w.Auth != WiFiNoPass && w.Password == "" {
+		return "", fmt.Errorf("wifi password is empty")
+	}
+	var s strings.Builder
+	s.WriteString("WIFI:T:")
+	s.WriteString(w.Auth.String())
+	s.WriteString(";S:")
+	s.WriteString(mecardEscape(w.SSID))
+	if w.Auth != WiFiNoPass {
+		s.WriteString(";P:")
+		s.WriteString(mecardEscape(w.Password))
+	}
+	if w.Hidden {
+		s.WriteString(";H:true")
+	}
+	s.WriteString(";;")
+	return s.String(), nil
+}
+
+// 解析配置字符串
+func ParseWiFi(str string) (*WiFi, error) {
+	if !hasPrefixFold(str, "WIFI:") {
+		return nil, fmt.Errorf("invalid wifi prefix")
+	}
+	fields, err := mecardFields(str[len("WIFI:"):])
+	if err != nil {
+		return nil, err
+	}
+	w := new(WiFi)
+	hasAuth := false
+	for _, f := range fields {
+		value := mecardUnescape(f[1])
+		switch strings.ToUpper(f[0]) {
+		case "T":
+			hasAuth = true
+			switch strings.ToUpper(value) {
+			case "", "NOPASS":
+				w.Auth = WiFiNoPass
+			case "WEP":
+				w.Auth = WiFiWEP
+			case "WPA":
+				w.Auth = WiFiWPA
+			case "WPA2":
+				w.Auth = WiFiWPA2
+			case "WPA3", "SAE":
+				w.Auth = WiFiWPA3
+			default:
+				return nil, fmt.Errorf("invalid wifi auth <%s>", value)
+			}
+		case "S":
+			w.SSID = value
+		case "P":
+			w.Password = value
+		case "H":
+			w.Hidden = strings.EqualFold(value, "true")
+		}
+	}
+	if w.SSID == "" {
+		return nil, fmt.Errorf("wifi ssid is empty")
+	}
+	// 没有T，有密码的按WPA处理
+	if !hasAuth && w.Password != "" {
+		w.Auth = WiFiWPA
+	}
+	return w, nil
+}
+
+// 拆分"K:V;K:V;;"，返回没有去掉转义的键值对，MeCard和Wi-Fi使用相同的格式
+func mecardFields(str string) ([][2]string, error) {
+	var fields [][2]string
+	for str != "" && str != ";" {
+		// 找到没有转义的';'
+		i := indexUnescaped(str, ';')
+		if i < 0 {
+			return nil, fmt.Errorf("field <%s> not terminated", str)
+		}
+		field := str[:i]
+		str = str[i+1:]
+		if field == "" {
+			break
+		}
+		k := strings.IndexByte(field, ':')
+		if k < 0 {
+			return nil, fmt.Errorf("invalid field <%s>", field)
+		}
+		fields = append(fields, [2]string{field[:k], field[k+1:]})
+	}
+	return fields, nil
+}
+
+// 第一个没有使用'\'转义的c的位置
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == c {
+			return i
+		}
+	}
+	return -1
+}
+
+// 使用没有转义的c拆分s，不去掉转义
+func splitUnescaped(s string, c byte) []string {
+	var ss []string
+	for {
+		i := indexUnescaped(s, c)
+		if i < 0 {
+			return append(ss, s)
+		}
+		ss = append(ss, s[:i])
+		s = s[i+1:]
+	}
+}
+
+// 转义'\'，';'，','，':'和'"'，MeCard和Wi-Fi使用相同的规则
+func mecardEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\', ';', ',', ':', '"':
+			b.WriteByte('\\')
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// 去掉转义
+func mecardUnescape(s string) string {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
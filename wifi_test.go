@@ -0,0 +1,37 @@
+package qrcode
+
+import (
+	"testing"
+)
+
+func TestWiFi(t *testing.T) {
+	w := &WiFi{SSID: `my;net:"1"`, Auth: WiFiWPA3, Password: `p\a,ss`, Hidden: true}
+	s, err := w.Text()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != `WIFI:T:SAE;S:my\;net\:\"1\";P:p\\a\,ss;H:true;;` {
+		t.Fatalf("unexpected wifi text <%s>", s)
+	}
+	p, err := ParseWiFi(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p != *w {
+		t.Fatalf("parse <%s> got %+v", s, p)
+	}
+	// 小写前缀
+	p, err = ParseWiFi("wifi:t:wpa;s:home;p:secret;;")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p != (WiFi{SSID: "home", Auth: WiFiWPA, Password: "secret"}) {
+		t.Fatalf("got %+v", p)
+	}
+	if _, err = (&WiFi{SSID: "x", Auth: WiFiWPA}).Text(); err == nil {
+		t.Fatal("expect error for empty password")
+	}
+	if _, err = ParseWiFi("WIFI:S:x"); err == nil {
+		t.Fatal("expect error for unterminated field")
+	}
+}
@@ -0,0 +1,94 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+var (
+	// ^BQ命令的纠错等级
+	zplLevelTable = [maxLevel]byte{
+		'L', 'M', 'Q', 'H',
+	}
+	// ^BQ命令手动输入的模式，字符串是UTF-8，日文模式也按字节输入
+	zplModeTable = [maxMode]byte{
+		'N', 'A', 'B', 'B',
+	}
+)
+
+// ZPL选项
+type ZPLOptions struct {
+	Options      // 共用选项，ModuleSize是每个模块的点数，^BQ命令最大是10
+	Native  bool // 使用^BQ命令由打印机生成二维码，否则输出^GFA图形
+	X, Y    int  // ^FO的位置，单位点
+	Label   bool // 输出完整的标签，包括^XA和^XZ
+}
+
+// 输出ZPL命令
+func ZPL(w io.Writer, str string, opt *ZPLOptions) error {
+	if opt == nil {
+		opt = new(ZPLOptions)
+	}
+	var buf bytes.Buffer
+	if opt.Label {
+		buf.WriteString("^XA\n")
+	}
+	fmt.Fprintf(&buf, "^FO%d,%d", opt.X, opt.Y)
+	var err error
+	if opt.Native {
+		err = opt.writeBQ(&buf, str)
+	} else {
+		err = opt.writeGF(&buf, str)
+	}
+	if err != nil {
+		return err
+	}
+	buf.WriteString("^FS\n")
+	if opt.Label {
+		buf.WriteString("^XZ\n")
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// ^BQ命令
+func (o *ZPLOptions) writeBQ(buf *bytes.Buffer, str string) error {
+	level := o.level()
+	// 检查长度
+	mode := analysisMode(str)
+	_, err := analysisVersion(str, level, mode)
+	if err != nil {
+		return err
+	}
+	mag := o.moduleSize()
+	if mag > 10 {
+		mag = 10
+	}
+	fmt.Fprintf(buf, "^BQN,2,%d", mag)
+	// 手动模式，^FH转义'^'，'~'和'_'
+	fmt.Fprintf(buf, "^FH_^FD%cM,%c", zplLevelTable[level], zplModeTable[mode])
+	if zplModeTable[mode] == 'B' {
+		fmt.Fprintf(buf, "%04d", len(str))
+	}
+	for i := 0; i < len(str); i++ {
+		switch str[i] {
+		case '^', '~', '_':
+			fmt.Fprintf(buf, "_%02X", str[i])
+		default:
+			buf.WriteByte(str[i])
+		}
+	}
+	return nil
+}
+
+// ^GFA图形
+func (o *ZPLOptions) writeGF(buf *bytes.Buffer, str string) error {
+	m, err := newMatrix(str, &o.Options)
+	if err != nil {
+		return err
+	}
+	rows, stride := m.bitmap(o.quietZone(), o.moduleSize())
+	fmt.Fprintf(buf, "^GFA,%d,%d,%d,%X", len(rows), len(rows), stride, rows)
+	return nil
+}
@@ -0,0 +1,69 @@
+package qrcode
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestZPL(t *testing.T) {
+	for _, c := range []struct {
+		str  string
+		opt  *ZPLOptions
+		want string
+	}{
+		{"12345", &ZPLOptions{Native: true}, "^FO0,0^BQN,2,1^FH_^FDLM,N12345^FS\n"},
+		{
+			"AB^C_D~",
+			&ZPLOptions{Options: Options{Level: LevelQ, ModuleSize: 12}, Native: true, X: 10, Y: 20, Label: true},
+			"^XA\n^FO10,20^BQN,2,10^FH_^FDQM,B0007AB_5EC_5FD_7E^FS\n^XZ\n",
+		},
+	} {
+		var out bytes.Buffer
+		if err := ZPL(&out, c.str, c.opt); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != c.want {
+			t.Fatalf("got <%s>, want <%s>", out.String(), c.want)
+		}
+	}
+	// ^GFA，29个点，每行4个字节，最后3个bit是填充
+	str := "HELLO WORLD"
+	m, err := newMatrix(str, &Options{Level: LevelM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err = ZPL(&out, str, &ZPLOptions{Options: Options{Level: LevelM}}); err != nil {
+		t.Fatal(err)
+	}
+	prefix := fmt.Sprintf("^FO0,0^GFA,%d,%d,%d,", 29*4, 29*4, 4)
+	s := out.String()
+	if !strings.HasPrefix(s, prefix) || !strings.HasSuffix(s, "^FS\n") {
+		t.Fatalf("invalid ^GFA <%.40s>", s)
+	}
+	s = strings.TrimSuffix(strings.TrimPrefix(s, prefix), "^FS\n")
+	if len(s) != 29*4*2 {
+		t.Fatalf("hex length got %d", len(s))
+	}
+	rows, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < 29; y++ {
+		row := rows[y*4 : y*4+4]
+		if row[3]&0x07 != 0 {
+			t.Fatalf("row %d padding bits are not zero", y)
+		}
+		for x := 0; x < 29; x++ {
+			dark := row[x/8]&(0x80>>(x%8)) != 0
+			mx, my := x-4, y-4
+			want := mx >= 0 && my >= 0 && mx < m.size && my < m.size && m.Dark(mx, my)
+			if dark != want {
+				t.Fatalf("pixel (%d,%d) got %v", x, y, dark)
+			}
+		}
+	}
+}